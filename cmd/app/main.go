@@ -15,11 +15,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-
 	"github.com/robfig/cron/v3"
 )
 
@@ -38,7 +33,7 @@ func parseLogLevel(levelStr string) slog.Level {
 	}
 }
 
-func runServices(appCfg *config.Config, jobCtx context.Context, storage *adapter.StorageAdapter) {
+func runServices(appCfg *config.Config, jobCtx context.Context, storage adapter.Storage, mirrored *adapter.MirrorStorage) {
 	mode := strings.ToLower(appCfg.AppMode)
 	slog.Info("Cron job terpicu.", "mode", mode)
 
@@ -59,6 +54,8 @@ func runServices(appCfg *config.Config, jobCtx context.Context, storage *adapter
 	if runAll || mode == "deletion" {
 		slog.Info("Memulai service: Deletion Queue")
 		service.ProcessDeletionQueue(
+			jobCtx,
+			appCfg,
 			appCfg.DeletionQueueBatchSize,
 			appCfg.DeletionQueueMaxRetries,
 			storage,
@@ -69,12 +66,38 @@ func runServices(appCfg *config.Config, jobCtx context.Context, storage *adapter
 	if runAll || mode == "cleanup" {
 		slog.Info("Memulai service: Cleanup Orphaned Files")
 		service.CleanupOrphanedFiles(
+			jobCtx,
 			appCfg,
 			appCfg.CleanupBatchSize,
 			storage,
 		)
 		slog.Info("Service Cleanup Orphaned Files selesai.")
 	}
+
+	if (runAll && appCfg.TrashEnabled) || mode == "trash-purge" {
+		slog.Info("Memulai service: Trash Purge")
+		service.PurgeTrash(jobCtx, appCfg, storage)
+		slog.Info("Service Trash Purge selesai.")
+	}
+
+	if (runAll && mirrored != nil) || mode == "mirror-repair" {
+		slog.Info("Memulai service: Mirror Repair")
+		service.ReconcileMirror(jobCtx, appCfg, mirrored)
+		service.ProcessMirrorRepairQueue(
+			jobCtx,
+			appCfg.MirrorRepairBatchSize,
+			appCfg.MirrorRepairMaxRetries,
+			mirrored,
+		)
+		slog.Info("Service Mirror Repair selesai.")
+	}
+
+	if (runAll && appCfg.TieringTarget != "") || mode == "tiering" {
+		slog.Info("Memulai service: Storage Tiering")
+		service.RunTiering(jobCtx, appCfg, storage)
+		slog.Info("Service Storage Tiering selesai.")
+	}
+	adapter.LogRateLimitWaits()
 	slog.Info("Semua service selesai.")
 }
 
@@ -90,6 +113,9 @@ func main() {
 	slog.SetDefault(logger)
 
 	database.ConnectDB(appCfg.DSN)
+	if err := database.EnsureSchema(); err != nil {
+		log.Fatalf("Gagal menyiapkan skema database: %v", err)
+	}
 
 	slog.Info("Aplikasi dimulai",
 		slog.String("schedule", appCfg.AppSchedule),
@@ -97,37 +123,11 @@ func main() {
 		slog.String("storage", appCfg.StorageMode),
 	)
 
-	var s3Client *s3.Client
-	if appCfg.StorageMode == "s3" {
-		slog.Info("Menginisialisasi AWS S3 Client...")
-
-		cfg, err := awsConfig.LoadDefaultConfig(context.TODO(),
-			awsConfig.WithRegion(appCfg.S3Region),
-			awsConfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-				appCfg.S3AccessKey,
-				appCfg.S3SecretKey,
-				"",
-			)),
-		)
-		if err != nil {
-			log.Fatalf("Gagal load config AWS: %v", err)
-		}
-
-		if appCfg.S3Endpoint != "" {
-			s3Client = s3.NewFromConfig(cfg, func(o *s3.Options) {
-				o.BaseEndpoint = aws.String(appCfg.S3Endpoint)
-				o.UsePathStyle = true
-				o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
-				o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
-			})
-		} else {
-			s3Client = s3.NewFromConfig(cfg)
-		}
-
+	storage, mirrored, err := service.BuildStorage(appCfg)
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi storage: %v", err)
 	}
 
-	storageAdapter := adapter.NewStorageAdapter(appCfg, s3Client)
-
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
@@ -135,7 +135,7 @@ func main() {
 	_, err = c.AddFunc(appCfg.AppSchedule, func() {
 		jobCtx, jobCancel := context.WithTimeout(ctx, 30*time.Minute)
 		defer jobCancel()
-		runServices(appCfg, jobCtx, storageAdapter)
+		runServices(appCfg, jobCtx, storage, mirrored)
 	})
 	if err != nil {
 		slog.Error("Gagal menambahkan cron job", "error", err)
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/service"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+func main() {
+	appCfg, err := config.LoadStorageConfig()
+	if err != nil {
+		log.Fatalf("Konfigurasi tidak valid: %v", err)
+	}
+
+	fromMode := flag.String("from", appCfg.StorageMode, "backend sumber (local, s3, memory)")
+	fromBucket := flag.String("from-bucket", "", "bucket S3 sumber (default S3_BUCKET)")
+	fromPrefix := flag.String("from-prefix", "", "prefix yang ditambahkan di depan path sumber")
+	toMode := flag.String("to", "", "backend tujuan (local, s3, memory)")
+	toBucket := flag.String("to-bucket", "", "bucket S3 tujuan (default S3_BUCKET)")
+	toPrefix := flag.String("to-prefix", "", "prefix yang ditambahkan di depan path tujuan")
+	name := flag.String("name", "", "nama checkpoint migrasi (default <from>-><to>)")
+	batchSize := flag.Int("batch-size", 200, "jumlah record file per batch")
+	dryRun := flag.Bool("dry-run", false, "hanya laporkan apa yang akan disalin")
+	retryFailed := flag.Bool("retry-failed", false, "ulangi file yang gagal pada run sebelumnya")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: parseLogLevel(appCfg.LogLevel),
+	}))
+	slog.SetDefault(logger)
+
+	if *toMode == "" {
+		log.Fatalf("Flag -to wajib diisi")
+	}
+	if *batchSize <= 0 {
+		log.Fatalf("Flag -batch-size harus lebih besar dari 0")
+	}
+	srcSpec := adapter.BackendSpec{Mode: *fromMode, S3Bucket: *fromBucket, Prefix: *fromPrefix}
+	dstSpec := adapter.BackendSpec{Mode: *toMode, S3Bucket: *toBucket, Prefix: *toPrefix}
+	if sameLocation(appCfg, srcSpec, dstSpec) {
+		log.Fatalf("Backend sumber dan tujuan menunjuk ke lokasi yang sama")
+	}
+	if *name == "" {
+		*name = fmt.Sprintf("%s->%s", describeBackend(appCfg, srcSpec), describeBackend(appCfg, dstSpec))
+	}
+
+	database.ConnectDB(appCfg.DSN)
+	if err := database.EnsureSchema(); err != nil {
+		log.Fatalf("Gagal menyiapkan skema database: %v", err)
+	}
+
+	src, err := adapter.NewBackend(appCfg, srcSpec)
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi storage sumber: %v", err)
+	}
+
+	dst, err := adapter.NewBackend(appCfg, dstSpec)
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi storage tujuan: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("Migrasi storage dimulai", "name", *name, "dry_run", *dryRun)
+	report, err := service.MigrateStorage(ctx, appCfg, src, dst, service.MigrationOptions{
+		Name:        *name,
+		BatchSize:   *batchSize,
+		DryRun:      *dryRun,
+		RetryFailed: *retryFailed,
+	})
+
+	adapter.LogRateLimitWaits()
+	slog.Info("Laporan migrasi storage",
+		"name", *name,
+		"dry_run", *dryRun,
+		"file", report.Files,
+		"file_arsip", report.Archived,
+		"file_gagal", report.Failed,
+		"objek", report.Objects,
+		"objek_disalin", report.Copied,
+		"objek_dilewati", report.Skipped,
+		"objek_hilang", report.Missing,
+		"bytes", report.CopiedBytes,
+	)
+	if err != nil {
+		slog.Error("Migrasi storage berhenti", "error", err)
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(2)
+	}
+}
+
+func describeBackend(cfg *config.Config, spec adapter.BackendSpec) string {
+	parts := []string{strings.ToLower(spec.Mode)}
+	if strings.EqualFold(spec.Mode, "s3") {
+		bucket := spec.S3Bucket
+		if bucket == "" {
+			bucket = cfg.S3Bucket
+		}
+		parts = append(parts, bucket)
+	}
+	if spec.Prefix != "" {
+		parts = append(parts, spec.Prefix)
+	}
+	return strings.Join(parts, ":")
+}
+
+func sameLocation(cfg *config.Config, a, b adapter.BackendSpec) bool {
+	return !strings.EqualFold(a.Mode, "memory") && describeBackend(cfg, a) == describeBackend(cfg, b)
+}
+
+func parseLogLevel(levelStr string) slog.Level {
+	switch strings.ToUpper(levelStr) {
+	case "DEBUG":
+		return slog.LevelDebug
+	case "INFO":
+		return slog.LevelInfo
+	case "WARN":
+		return slog.LevelWarn
+	case "ERROR":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
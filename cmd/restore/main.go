@@ -0,0 +1,77 @@
+package main
+
+import (
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/service"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	entryID := flag.Int("id", 0, "id entri trash yang akan dipulihkan")
+	list := flag.Bool("list", false, "tampilkan entri trash yang masih bisa dipulihkan")
+	limit := flag.Int("limit", 50, "jumlah entri yang ditampilkan dengan -list")
+	flag.Parse()
+
+	appCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Konfigurasi tidak valid: %v", err)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	database.ConnectDB(appCfg.DSN)
+	if err := database.EnsureSchema(); err != nil {
+		log.Fatalf("Gagal menyiapkan skema database: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *list {
+		entries, err := service.ListTrashEntries(ctx, *limit)
+		if err != nil {
+			log.Fatalf("Gagal membaca entri trash: %v", err)
+		}
+		for _, entry := range entries {
+			fileID := "-"
+			if entry.FileID != nil {
+				fileID = fmt.Sprint(*entry.FileID)
+			}
+			trashPath := "-"
+			if entry.TrashPath != nil {
+				trashPath = *entry.TrashPath
+			}
+			fmt.Printf("%d\t%s\tfile=%s\t%s\t%s\t%s\n",
+				entry.ID,
+				time.Unix(entry.DeletedAt, 0).Format(time.RFC3339),
+				fileID,
+				entry.Reason,
+				entry.OriginalPath,
+				trashPath,
+			)
+		}
+		return
+	}
+
+	if *entryID <= 0 {
+		log.Fatalf("Gunakan -list atau -id <entri trash>")
+	}
+
+	storage, _, err := service.BuildStorage(appCfg)
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi storage: %v", err)
+	}
+
+	if err := service.RestoreFromTrash(ctx, storage, int32(*entryID)); err != nil {
+		log.Fatalf("Gagal memulihkan entri trash %d: %v", *entryID, err)
+	}
+	slog.Info("Entri trash berhasil dipulihkan", "id", *entryID)
+}
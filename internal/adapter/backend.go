@@ -0,0 +1,120 @@
+package adapter
+
+import (
+	"chrononews-scheduler/internal/config"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// BackendSpec menjelaskan backend tambahan (misalnya tier arsip) yang memakai
+// kredensial S3, timeout dan retry yang sama dengan backend utama. Prefix
+// ditambahkan di depan setiap path sehingga layout DIR_* tetap sama.
+type BackendSpec struct {
+	Mode     string
+	S3Bucket string
+	Prefix   string
+}
+
+func NewBackend(cfg *config.Config, spec BackendSpec) (Storage, error) {
+	derived := *cfg
+	derived.StorageMode = strings.ToLower(spec.Mode)
+	if spec.S3Bucket != "" {
+		derived.S3Bucket = spec.S3Bucket
+	}
+
+	storage, err := NewStorage(&derived)
+	if err != nil {
+		return nil, err
+	}
+	return WithPrefix(storage, spec.Prefix), nil
+}
+
+type prefixStorage struct {
+	next   Storage
+	prefix string
+}
+
+func WithPrefix(next Storage, prefix string) Storage {
+	prefix = normalizePrefix(prefix)
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return next
+	}
+	return &prefixStorage{next: next, prefix: prefix}
+}
+
+func (s *prefixStorage) join(path string) string {
+	return filepath.Join(filepath.FromSlash(s.prefix), path)
+}
+
+func (s *prefixStorage) trim(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(path), s.prefix+"/")
+}
+
+func (s *prefixStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.next.Open(ctx, s.join(path))
+}
+
+func (s *prefixStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	return s.next.Put(ctx, s.join(path), reader, opts)
+}
+
+func (s *prefixStorage) Delete(ctx context.Context, path string) error {
+	return s.next.Delete(ctx, s.join(path))
+}
+
+func (s *prefixStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	joined := make([]string, len(paths))
+	for i, path := range paths {
+		joined[i] = s.join(path)
+	}
+	results := s.next.DeleteMany(ctx, joined)
+	for i := range results {
+		results[i].Path = paths[i]
+	}
+	return results
+}
+
+func (s *prefixStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	info, err := s.next.Stat(ctx, s.join(path))
+	if err != nil {
+		return info, err
+	}
+	info.Path = s.trim(info.Path)
+	return info, nil
+}
+
+func (s *prefixStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return s.next.Exists(ctx, s.join(path))
+}
+
+func (s *prefixStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	opts.Prefix = s.prefix + "/" + normalizePrefix(opts.Prefix)
+
+	page, err := s.next.List(ctx, opts)
+	if err != nil {
+		return page, err
+	}
+	for i := range page.Objects {
+		page.Objects[i].Path = s.trim(page.Objects[i].Path)
+	}
+	return page, nil
+}
+
+func (s *prefixStorage) SetStorageClass(ctx context.Context, path, class string) error {
+	return setStorageClass(ctx, s.next, s.join(path), class)
+}
+
+func (s *prefixStorage) Move(ctx context.Context, from, to string) error {
+	return Move(ctx, s.next, s.join(from), s.join(to))
+}
+
+func (s *prefixStorage) Unwrap() Storage {
+	return s.next
+}
+
+func (s *prefixStorage) Copy(ctx context.Context, from, to string) error {
+	return Copy(ctx, s.next, s.join(from), s.join(to))
+}
@@ -0,0 +1,60 @@
+package adapter
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// ErrChecksumMismatch menandakan objek yang tersimpan tidak sama dengan byte
+// yang dikirim. Error ini tidak di-retry karena objeknya perlu ditulis ulang.
+var ErrChecksumMismatch = errors.New("checksum objek tidak cocok")
+
+func isHexMD5(value string) bool {
+	if len(value) != md5.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+// multipartETag menghitung ETag gabungan S3: MD5 dari gabungan MD5 tiap part,
+// diikuti jumlah part.
+func multipartETag(partSums [][]byte) string {
+	hash := md5.New()
+	for _, sum := range partSums {
+		hash.Write(sum)
+	}
+	return hex.EncodeToString(hash.Sum(nil)) + "-" + strconv.Itoa(len(partSums))
+}
+
+// verifyETag membandingkan ETag dari S3 dengan nilai yang dihitung saat
+// upload. ETag yang formatnya bukan MD5 (misalnya objek SSE-KMS atau provider
+// yang tidak mengikuti konvensi S3) tidak bisa diverifikasi dan dilewati.
+func verifyETag(key, etag, expected string) error {
+	digest, parts, multipart := strings.Cut(etag, "-")
+	if !isHexMD5(digest) || (multipart && !strings.HasSuffix(expected, "-"+parts)) {
+		slog.Debug("ETag tidak bisa diverifikasi, dilewati", "key", key, "etag", etag)
+		return nil
+	}
+	if !strings.EqualFold(etag, expected) {
+		return fmt.Errorf("%w: %s memiliki ETag %s, diharapkan %s", ErrChecksumMismatch, key, etag, expected)
+	}
+	return nil
+}
+
+// mapChecksumError menandai penolakan Content-MD5 oleh server sebagai
+// ErrChecksumMismatch.
+func mapChecksumError(key string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "BadDigest" || apiErr.ErrorCode() == "InvalidDigest") {
+		return fmt.Errorf("%w: %s ditolak server (%s)", ErrChecksumMismatch, key, apiErr.ErrorCode())
+	}
+	return err
+}
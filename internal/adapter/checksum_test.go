@@ -0,0 +1,70 @@
+package adapter
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestMultipartETag(t *testing.T) {
+	a, b := md5.Sum([]byte("a")), md5.Sum([]byte("b"))
+	if got, want := multipartETag([][]byte{a[:], b[:]}), "96e024ba2074fe77e8e965ba43a704be-2"; got != want {
+		t.Errorf("multipartETag = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyETag(t *testing.T) {
+	const single = "187ef4436122d1cc2f40dc2b92f0eba0"
+	const multi = "96e024ba2074fe77e8e965ba43a704be-2"
+
+	tests := []struct {
+		name     string
+		etag     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "cocok", etag: single, expected: single},
+		{name: "huruf besar", etag: strings.ToUpper(single), expected: single},
+		{name: "tidak cocok", etag: strings.Repeat("0", 32), expected: single, wantErr: true},
+		{name: "multipart cocok", etag: multi, expected: multi},
+		{name: "multipart tidak cocok", etag: strings.Repeat("0", 32) + "-2", expected: multi, wantErr: true},
+		{name: "jumlah part beda dilewati", etag: strings.Repeat("0", 32) + "-3", expected: multi},
+		{name: "bukan MD5 dilewati", etag: "kms-managed-etag", expected: single},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyETag("a.webp", tt.etag, tt.expected)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrChecksumMismatch)) {
+				t.Errorf("verifyETag = %v, want ErrChecksumMismatch %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMapChecksumError(t *testing.T) {
+	for code, want := range map[string]bool{"BadDigest": true, "InvalidDigest": true, "AccessDenied": false} {
+		err := mapChecksumError("a.webp", &smithy.GenericAPIError{Code: code})
+		if errors.Is(err, ErrChecksumMismatch) != want {
+			t.Errorf("mapChecksumError(%s) = %v, want ErrChecksumMismatch %v", code, err, want)
+		}
+	}
+}
+
+func TestS3PutDeletesObjectWithWrongETag(t *testing.T) {
+	for _, data := range []string{"1234", "123456789012"} {
+		storage, fake := newTestS3Storage(t, true)
+		fake.wrongETag = true
+
+		err := storage.Put(context.Background(), "a.webp", strings.NewReader(data), PutOptions{})
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Put %d byte: err = %v, want ErrChecksumMismatch", len(data), err)
+		}
+		if _, ok := fake.objects["a.webp"]; ok {
+			t.Errorf("Put %d byte: objek dengan checksum salah tidak dihapus", len(data))
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package adapter
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const fakeBucket = "chrononews"
+
+// fakeS3 meniru subset API S3 (path-style) yang dipakai S3Storage: objek
+// tunggal, multipart upload, HeadObject dan DeleteObjects.
+type fakeS3 struct {
+	mu            sync.Mutex
+	objects       map[string][]byte
+	parts         map[string]map[int][]byte
+	uploads       int
+	aborted       int
+	partSizes     []int
+	deleteBatches [][]string
+	// deleteErrors berisi kode error per key untuk respons DeleteObjects.
+	deleteErrors map[string]string
+	// failures berisi status HTTP yang dikembalikan berurutan sebelum
+	// request dilayani normal.
+	failures []int
+	// wrongETag membuat respons PutObject dan CompleteMultipartUpload
+	// membawa ETag yang tidak cocok dengan isi objek.
+	wrongETag bool
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *s3.Client) {
+	t.Helper()
+
+	fake := &fakeS3{
+		objects:      map[string][]byte{},
+		parts:        map[string]map[int][]byte{},
+		deleteErrors: map[string]string{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:                     "us-east-1",
+		BaseEndpoint:               aws.String(server.URL),
+		UsePathStyle:               true,
+		Credentials:                aws.AnonymousCredentials{},
+		RetryMaxAttempts:           1,
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+	return fake, client
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.failures) > 0 {
+		status := f.failures[0]
+		f.failures = f.failures[1:]
+		w.WriteHeader(status)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+fakeBucket+"/")
+	query := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.uploads++
+		uploadID := strconv.Itoa(f.uploads)
+		f.parts[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", fakeBucket, key, uploadID)
+
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.parts[query.Get("uploadId")][number] = body
+		f.partSizes = append(f.partSizes, len(body))
+		w.Header().Set("ETag", fmt.Sprintf("%q", md5Hex(body)))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts := f.parts[query.Get("uploadId")]
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var object []byte
+		var sums [][]byte
+		for _, number := range numbers {
+			object = append(object, parts[number]...)
+			sum := md5.Sum(parts[number])
+			sums = append(sums, sum[:])
+		}
+		f.objects[key] = object
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>%q</ETag></CompleteMultipartUploadResult>", fakeBucket, key, f.etag(multipartETag(sums)))
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		f.aborted++
+		delete(f.parts, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPost && query.Has("delete"):
+		f.deleteObjects(w, body)
+
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		w.Header().Set("ETag", fmt.Sprintf("%q", f.etag(md5Hex(body))))
+
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
+			}
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(object)))
+		w.Header().Set("ETag", fmt.Sprintf("%q", md5Hex(object)))
+		if r.Method == http.MethodGet {
+			w.Write(object)
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) deleteObjects(w http.ResponseWriter, body []byte) {
+	var request struct {
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var keys []string
+	var result strings.Builder
+	result.WriteString("<DeleteResult>")
+	for _, object := range request.Objects {
+		keys = append(keys, object.Key)
+		if code, ok := f.deleteErrors[object.Key]; ok {
+			fmt.Fprintf(&result, "<Error><Key>%s</Key><Code>%s</Code><Message>ditolak</Message></Error>", object.Key, code)
+			continue
+		}
+		delete(f.objects, object.Key)
+	}
+	result.WriteString("</DeleteResult>")
+	f.deleteBatches = append(f.deleteBatches, keys)
+	fmt.Fprint(w, result.String())
+}
+
+func (f *fakeS3) etag(etag string) string {
+	if f.wrongETag {
+		return strings.Repeat("0", 32) + etag[32:]
+	}
+	return etag
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,400 @@
+package adapter
+
+import (
+	"bytes"
+	"chrononews-scheduler/internal/config"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	tempFilePrefix   = ".chrononews-tmp-"
+	staleTempFileAge = time.Hour
+)
+
+func init() {
+	Register("local", func(cfg *config.Config) (Storage, error) {
+		storage := NewLocalStorage()
+		storage.verify = cfg.StorageVerifyUploads
+		sweepLocalRootsOnce.Do(func() {
+			storage.SweepStaleTempFiles(localRoots(cfg), staleTempFileAge)
+		})
+		return storage, nil
+	})
+}
+
+// sweepLocalRootsOnce mencegah penyapuan berulang saat backend lokal dibuat
+// lebih dari sekali (primary, mirror dan arsip).
+var sweepLocalRootsOnce sync.Once
+
+// localRoots mengembalikan setiap folder yang bisa ditulisi backend lokal:
+// DIR_*, TRASH_PREFIX, serta MIRROR_PREFIX dan ARCHIVE_PREFIX bila backend
+// tersebut lokal.
+func localRoots(cfg *config.Config) []string {
+	roots := []string{cfg.DirAttachment, cfg.DirProfile, cfg.DirThumbnail}
+	if cfg.TrashEnabled {
+		roots = append(roots, cfg.TrashPrefix)
+	}
+	if strings.EqualFold(cfg.MirrorStorageMode, "local") {
+		roots = append(roots, cfg.MirrorPrefix)
+	}
+	if strings.EqualFold(cfg.ArchiveStorageMode, "local") {
+		roots = append(roots, cfg.ArchivePrefix)
+	}
+	return roots
+}
+
+// LocalStorage.verify membaca ulang file sementara setelah fsync dan
+// mencocokkan SHA-256-nya dengan byte yang ditulis sebelum file di-rename.
+type LocalStorage struct {
+	verify bool
+}
+
+func NewLocalStorage() *LocalStorage {
+	return &LocalStorage{}
+}
+
+func (s *LocalStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &contextReadCloser{contextReader: contextReader{ctx: ctx, reader: file}, closer: file}, nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Sidecar baru disiapkan sebagai file sementara dan baru menggantikan
+	// sidecar lama setelah objek berhasil di-rename, sehingga penulisan yang
+	// gagal tidak menyentuh objek maupun header yang sudah ada.
+	sidecarTmp, err := stageSidecar(ctx, path, opts)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(ctx, path, reader, s.verify); err != nil {
+		discardTempFile(sidecarTmp)
+		return err
+	}
+	return commitSidecar(sidecarTmp, path)
+}
+
+func writeFileAtomic(ctx context.Context, path string, reader io.Reader, verify bool) error {
+	tmpPath, err := writeTempFile(ctx, path, reader, verify)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		discardTempFile(tmpPath)
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := syncDir(dir); err != nil {
+		slog.Warn("Gagal fsync folder setelah rename", "dir", dir, "error", err)
+	}
+	return nil
+}
+
+// writeTempFile menulis reader ke file sementara di folder path dan
+// mengembalikan path file tersebut setelah di-fsync. File sementara dihapus
+// bila penulisan gagal.
+func writeTempFile(ctx context.Context, path string, reader io.Reader, verify bool) (string, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	tmpFile, err := os.CreateTemp(dir, tempFilePrefix+filepath.Base(path)+"-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmpFile.Name()
+
+	committed := false
+	defer func() {
+		if !committed {
+			discardTempFile(tmpPath)
+		}
+	}()
+
+	source := io.Reader(&contextReader{ctx: ctx, reader: reader})
+	written := sha256.New()
+	if verify {
+		source = io.TeeReader(source, written)
+	}
+
+	_, copyErr := io.Copy(tmpFile, source)
+	if copyErr == nil {
+		copyErr = tmpFile.Sync()
+	}
+
+	closeErr := tmpFile.Close()
+
+	if copyErr != nil {
+		return "", copyErr
+	}
+	if closeErr != nil {
+		return "", closeErr
+	}
+	if verify {
+		if err := verifyFileSHA256(tmpPath, written.Sum(nil)); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return "", err
+	}
+	committed = true
+	return tmpPath, nil
+}
+
+func discardTempFile(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Gagal menghapus file sementara", "path", path, "error", err)
+	}
+}
+
+func verifyFileSHA256(path string, expected []byte) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if !bytes.Equal(hash.Sum(nil), expected) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	syncErr := d.Sync()
+	closeErr := d.Close()
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, tempFilePrefix)
+}
+
+func (s *LocalStorage) SweepStaleTempFiles(dirs []string, olderThan time.Duration) int {
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() || !isTempFile(d.Name()) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || info.ModTime().After(cutoff) {
+				return nil
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				slog.Warn("Gagal menghapus file sementara basi", "path", path, "error", err)
+				return nil
+			}
+			removed++
+			return nil
+		})
+		if err != nil {
+			slog.Warn("Gagal menyapu file sementara", "dir", dir, "error", err)
+		}
+	}
+
+	if removed > 0 {
+		slog.Info("File sementara basi dihapus", "jumlah", removed)
+	}
+	return removed
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := os.Remove(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			slog.Debug("File lokal tidak ditemukan saat penghapusan", "path", path)
+			return removeSidecar(path)
+		}
+		return err
+	}
+	return removeSidecar(path)
+}
+
+// Move memakai rename sehingga pemindahan di filesystem yang sama bersifat
+// atomik. Pemindahan lintas filesystem jatuh ke salin lalu hapus.
+func (s *LocalStorage) Move(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(to)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		if errors.Is(err, syscall.EXDEV) {
+			return copyThenDelete(ctx, s, from, to)
+		}
+		return err
+	}
+
+	// Sidecar tujuan baru diganti setelah objek berpindah: sidecar sumber
+	// menimpanya, atau sidecar lama dihapus bila sumber tidak punya sidecar.
+	if err := os.Rename(sidecarPath(from), sidecarPath(to)); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if err := removeSidecar(to); err != nil {
+			return err
+		}
+	}
+
+	if err := syncDir(dir); err != nil {
+		slog.Warn("Gagal fsync folder setelah rename", "dir", dir, "error", err)
+	}
+	return nil
+}
+
+const localDeleteConcurrency = 8
+
+func (s *LocalStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	results := make([]DeleteResult, len(paths))
+	sem := make(chan struct{}, localDeleteConcurrency)
+	var wg sync.WaitGroup
+
+	for i, path := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = DeleteResult{Path: path, Err: s.Delete(ctx, path)}
+		}(i, path)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func localObjectInfo(path string, info fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Path:        path,
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+		ETag:        fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+	}
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if info.IsDir() {
+		return ObjectInfo{}, fmt.Errorf("%s adalah folder: %w", path, fs.ErrNotExist)
+	}
+
+	objectInfo := localObjectInfo(path, info)
+	sidecar, found, err := readSidecar(path)
+	if err != nil {
+		slog.Warn("Gagal membaca sidecar metadata", "path", path, "error", err)
+	} else if found {
+		sidecar.applyTo(&objectInfo)
+	}
+	return objectInfo, nil
+}
+
+func (s *LocalStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return existsFromStat(ctx, s, path)
+}
+
+func (s *LocalStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	prefix := normalizePrefix(opts.Prefix)
+
+	root := "."
+	if prefix != "" {
+		root = filepath.FromSlash(prefix)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			root = filepath.Dir(root)
+		}
+	}
+
+	var objects []ObjectInfo
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || isTempFile(d.Name()) || isSidecarFile(d.Name()) || !strings.HasPrefix(filepath.ToSlash(path), prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, localObjectInfo(path, info))
+		return nil
+	})
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	return paginateSorted(objects, opts), nil
+}
@@ -0,0 +1,91 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+const metadataSidecarSuffix = ".meta.json"
+
+// localSidecar menyimpan header dan metadata objek lokal yang di S3 disimpan
+// sebagai Cache-Control, Content-Disposition dan x-amz-meta-*.
+type localSidecar struct {
+	ContentType        string            `json:"content_type,omitempty"`
+	CacheControl       string            `json:"cache_control,omitempty"`
+	ContentDisposition string            `json:"content_disposition,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+}
+
+func sidecarPath(path string) string {
+	return path + metadataSidecarSuffix
+}
+
+func isSidecarFile(name string) bool {
+	return strings.HasSuffix(name, metadataSidecarSuffix)
+}
+
+func (m localSidecar) applyTo(info *ObjectInfo) {
+	if m.ContentType != "" {
+		info.ContentType = m.ContentType
+	}
+	info.CacheControl = m.CacheControl
+	info.ContentDisposition = m.ContentDisposition
+	info.Metadata = m.Metadata
+}
+
+// stageSidecar menulis sidecar untuk opts ke file sementara dan mengembalikan
+// path-nya. Path kosong berarti objek tidak membutuhkan sidecar.
+func stageSidecar(ctx context.Context, path string, opts PutOptions) (string, error) {
+	if opts.CacheControl == "" && opts.ContentDisposition == "" && len(opts.Metadata) == 0 {
+		return "", nil
+	}
+
+	data, err := json.MarshalIndent(localSidecar{
+		ContentType:        opts.ContentType,
+		CacheControl:       opts.CacheControl,
+		ContentDisposition: opts.ContentDisposition,
+		Metadata:           opts.Metadata,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return writeTempFile(ctx, sidecarPath(path), bytes.NewReader(data), false)
+}
+
+// commitSidecar memasang sidecar hasil stageSidecar untuk path, atau
+// menghapus sidecar lama bila tmpPath kosong.
+func commitSidecar(tmpPath, path string) error {
+	if tmpPath == "" {
+		return removeSidecar(path)
+	}
+	if err := os.Rename(tmpPath, sidecarPath(path)); err != nil {
+		discardTempFile(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func readSidecar(path string) (localSidecar, bool, error) {
+	var sidecar localSidecar
+	data, err := os.ReadFile(sidecarPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sidecar, false, nil
+		}
+		return sidecar, false, err
+	}
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return sidecar, false, err
+	}
+	return sidecar, true, nil
+}
+
+func removeSidecar(path string) error {
+	if err := os.Remove(sidecarPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("baca gagal")
+}
+
+func TestLocalStoragePutWritesObjectAndSidecar(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLocalStorage()
+	path := filepath.Join(t.TempDir(), "post_picture", "a.webp")
+
+	err := storage.Put(ctx, path, strings.NewReader("webp"), PutOptions{
+		ContentType:  "image/webp",
+		CacheControl: "max-age=60",
+		Metadata:     map[string]string{"file-id": "7"},
+	})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	info, err := storage.Stat(ctx, path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != 4 || info.ContentType != "image/webp" || info.CacheControl != "max-age=60" || info.Metadata["file-id"] != "7" {
+		t.Errorf("Stat = %+v", info)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+
+	// Put tanpa header menghapus sidecar lama.
+	if err := storage.Put(ctx, path, strings.NewReader("webp2"), PutOptions{ContentType: "image/webp"}); err != nil {
+		t.Fatalf("Put kedua: %v", err)
+	}
+	if _, err := os.Stat(sidecarPath(path)); !os.IsNotExist(err) {
+		t.Errorf("sidecar lama masih ada: %v", err)
+	}
+}
+
+func TestLocalStorageFailedPutKeepsExistingObject(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLocalStorage()
+	path := filepath.Join(t.TempDir(), "a.webp")
+
+	if err := storage.Put(ctx, path, strings.NewReader("lama"), PutOptions{CacheControl: "max-age=60"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := storage.Put(ctx, path, failingReader{}, PutOptions{CacheControl: "no-store"}); err == nil {
+		t.Fatalf("Put dengan reader gagal tidak mengembalikan error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "lama" {
+		t.Errorf("objek = %q, %v; want lama", data, err)
+	}
+	info, err := storage.Stat(ctx, path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.CacheControl != "max-age=60" {
+		t.Errorf("CacheControl = %q, want sidecar lama max-age=60", info.CacheControl)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestLocalStorageMoveCarriesSidecar(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLocalStorage()
+	dir := t.TempDir()
+	from := filepath.Join(dir, "a.webp")
+	to := filepath.Join(dir, "trash", "a.webp")
+
+	if err := storage.Put(ctx, from, strings.NewReader("baru"), PutOptions{CacheControl: "max-age=60"}); err != nil {
+		t.Fatalf("Put sumber: %v", err)
+	}
+	if err := storage.Put(ctx, to, strings.NewReader("lama"), PutOptions{CacheControl: "no-store"}); err != nil {
+		t.Fatalf("Put tujuan: %v", err)
+	}
+
+	if err := storage.Move(ctx, from, to); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	info, err := storage.Stat(ctx, to)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != 4 || info.CacheControl != "max-age=60" {
+		t.Errorf("Stat tujuan = %+v, want sidecar sumber", info)
+	}
+	if _, err := os.Stat(sidecarPath(from)); !os.IsNotExist(err) {
+		t.Errorf("sidecar sumber masih ada: %v", err)
+	}
+}
+
+func TestLocalStorageFailedMoveKeepsTargetSidecar(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLocalStorage()
+	dir := t.TempDir()
+	to := filepath.Join(dir, "a.webp")
+
+	if err := storage.Put(ctx, to, strings.NewReader("lama"), PutOptions{CacheControl: "no-store"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := storage.Move(ctx, filepath.Join(dir, "missing.webp"), to); err == nil {
+		t.Fatalf("Move dari path kosong tidak mengembalikan error")
+	}
+
+	info, err := storage.Stat(ctx, to)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.CacheControl != "no-store" {
+		t.Errorf("CacheControl = %q, want no-store", info.CacheControl)
+	}
+}
+
+func TestLocalStoragePutWithVerify(t *testing.T) {
+	storage := NewLocalStorage()
+	storage.verify = true
+	path := filepath.Join(t.TempDir(), "a.webp")
+
+	if err := storage.Put(context.Background(), path, strings.NewReader("webp"), PutOptions{}); err != nil {
+		t.Fatalf("Put dengan verifikasi: %v", err)
+	}
+	reader, err := storage.Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer reader.Close()
+	if data, _ := io.ReadAll(reader); string(data) != "webp" {
+		t.Errorf("isi = %q, want webp", data)
+	}
+}
+
+func TestSweepStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, tempFilePrefix+"a.webp-1")
+	fresh := filepath.Join(dir, tempFilePrefix+"b.webp-2")
+	kept := filepath.Join(dir, "c.webp")
+	for _, path := range []string{stale, fresh, kept} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(kept, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed := NewLocalStorage().SweepStaleTempFiles([]string{dir}, time.Hour); removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	for path, wantExists := range map[string]bool{stale: false, fresh: true, kept: true} {
+		if _, err := os.Stat(path); (err == nil) != wantExists {
+			t.Errorf("%s ada = %v, want %v", filepath.Base(path), err == nil, wantExists)
+		}
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if isTempFile(entry.Name()) {
+			t.Errorf("file sementara tertinggal: %s", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package adapter
+
+import (
+	"bytes"
+	"chrononews-scheduler/internal/config"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("memory", func(cfg *config.Config) (Storage, error) {
+		if cfg.MemoryFallbackStorageMode == "" {
+			return NewMemoryStorage(nil), nil
+		}
+		// Timeout dan rate limiter sudah dipasang NewStorage di luar
+		// MemoryStorage, jadi fallback memakai factory mentah.
+		fallback, err := newBackendStorage(cfg, cfg.MemoryFallbackStorageMode)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuat fallback storage memory: %w", err)
+		}
+		return NewMemoryStorage(fallback), nil
+	})
+}
+
+const (
+	OpOpen   = "open"
+	OpPut    = "put"
+	OpDelete = "delete"
+	OpStat   = "stat"
+	OpList   = "list"
+)
+
+type StorageCall struct {
+	Op          string
+	Path        string
+	Size        int64
+	ContentType string
+	Options     PutOptions
+	Fallback    bool
+	Err         error
+	At          time.Time
+}
+
+// memoryCallLogSize membatasi jumlah StorageCall yang disimpan. Panggilan
+// tertua ditimpa setelah batas tercapai; jumlah dan total byte per operasi
+// tetap dihitung lewat CallStats.
+const memoryCallLogSize = 10000
+
+// CallStat adalah total panggilan dan byte per operasi sejak Reset.
+type CallStat struct {
+	Count int
+	Bytes int64
+}
+
+type memoryObject struct {
+	data    []byte
+	opts    PutOptions
+	modTime time.Time
+}
+
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	objects  map[string]memoryObject
+	calls    []StorageCall
+	next     int
+	stats    map[string]CallStat
+	fallback Storage
+}
+
+// NewMemoryStorage membuat storage in-memory. Jika fallback tidak nil, Open
+// untuk path yang belum pernah di-Put akan dibaca dari fallback, sedangkan
+// Put dan Delete tetap hanya menyentuh memori.
+func NewMemoryStorage(fallback Storage) *MemoryStorage {
+	return &MemoryStorage{
+		objects:  make(map[string]memoryObject),
+		stats:    make(map[string]CallStat),
+		fallback: fallback,
+	}
+}
+
+func memoryKey(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func (s *MemoryStorage) record(call StorageCall) {
+	call.At = time.Now()
+
+	stat := s.stats[call.Op]
+	stat.Count++
+	if call.Size > 0 {
+		stat.Bytes += call.Size
+	}
+	s.stats[call.Op] = stat
+
+	if len(s.calls) < memoryCallLogSize {
+		s.calls = append(s.calls, call)
+		return
+	}
+	s.calls[s.next] = call
+	s.next = (s.next + 1) % memoryCallLogSize
+}
+
+func (s *MemoryStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := memoryKey(path)
+
+	s.mu.Lock()
+	if obj, ok := s.objects[key]; ok {
+		s.record(StorageCall{Op: OpOpen, Path: key, Size: int64(len(obj.data)), ContentType: obj.opts.ContentType})
+		s.mu.Unlock()
+		return io.NopCloser(bytes.NewReader(obj.data)), nil
+	}
+	s.mu.Unlock()
+
+	if s.fallback != nil {
+		reader, err := s.fallback.Open(ctx, path)
+		s.mu.Lock()
+		s.record(StorageCall{Op: OpOpen, Path: key, Size: -1, Fallback: true, Err: err})
+		s.mu.Unlock()
+		return reader, err
+	}
+
+	err := fmt.Errorf("memory storage: %s: %w", key, fs.ErrNotExist)
+	s.mu.Lock()
+	s.record(StorageCall{Op: OpOpen, Path: key, Err: err})
+	s.mu.Unlock()
+	return nil, err
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key := memoryKey(path)
+
+	data, err := io.ReadAll(&contextReader{ctx: ctx, reader: reader})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.record(StorageCall{Op: OpPut, Path: key, Size: int64(len(data)), ContentType: opts.ContentType, Options: opts, Err: err})
+		return err
+	}
+
+	s.objects[key] = memoryObject{data: data, opts: opts, modTime: time.Now()}
+	s.record(StorageCall{Op: OpPut, Path: key, Size: int64(len(data)), ContentType: opts.ContentType, Options: opts})
+	return nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key := memoryKey(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var size int64
+	if obj, ok := s.objects[key]; ok {
+		size = int64(len(obj.data))
+		delete(s.objects, key)
+	}
+	s.record(StorageCall{Op: OpDelete, Path: key, Size: size})
+	return nil
+}
+
+func (s *MemoryStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	results := make([]DeleteResult, len(paths))
+	for i, path := range paths {
+		results[i] = DeleteResult{Path: path, Err: s.Delete(ctx, path)}
+	}
+	return results
+}
+
+func (s *MemoryStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return ObjectInfo{}, err
+	}
+	key := memoryKey(path)
+
+	s.mu.Lock()
+	obj, ok := s.objects[key]
+	if ok {
+		s.record(StorageCall{Op: OpStat, Path: key, Size: int64(len(obj.data)), ContentType: obj.opts.ContentType})
+		s.mu.Unlock()
+		return memoryObjectInfo(key, obj), nil
+	}
+	s.mu.Unlock()
+
+	if s.fallback != nil {
+		info, err := s.fallback.Stat(ctx, path)
+		s.mu.Lock()
+		s.record(StorageCall{Op: OpStat, Path: key, Size: info.Size, ContentType: info.ContentType, Fallback: true, Err: err})
+		s.mu.Unlock()
+		return info, err
+	}
+
+	err := fmt.Errorf("memory storage: %s: %w", key, fs.ErrNotExist)
+	s.mu.Lock()
+	s.record(StorageCall{Op: OpStat, Path: key, Err: err})
+	s.mu.Unlock()
+	return ObjectInfo{}, err
+}
+
+func (s *MemoryStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return existsFromStat(ctx, s, path)
+}
+
+func (s *MemoryStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	if err := ctx.Err(); err != nil {
+		return ListPage{}, err
+	}
+	prefix := normalizePrefix(opts.Prefix)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var objects []ObjectInfo
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, memoryObjectInfo(key, obj))
+		}
+	}
+	s.record(StorageCall{Op: OpList, Path: prefix})
+
+	return paginateSorted(objects, opts), nil
+}
+
+func memoryObjectInfo(key string, obj memoryObject) ObjectInfo {
+	return ObjectInfo{
+		Path:               key,
+		Size:               int64(len(obj.data)),
+		ModTime:            obj.modTime,
+		ContentType:        obj.opts.ContentType,
+		ETag:               fmt.Sprintf("%x-%x", obj.modTime.UnixNano(), len(obj.data)),
+		CacheControl:       obj.opts.CacheControl,
+		ContentDisposition: obj.opts.ContentDisposition,
+		Metadata:           obj.opts.Metadata,
+	}
+}
+
+// Calls mengembalikan paling banyak memoryCallLogSize panggilan terakhir,
+// urut dari yang tertua.
+func (s *MemoryStorage) Calls() []StorageCall {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	calls := make([]StorageCall, 0, len(s.calls))
+	calls = append(calls, s.calls[s.next:]...)
+	return append(calls, s.calls[:s.next]...)
+}
+
+func (s *MemoryStorage) CallStats(op string) CallStat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.stats[op]
+}
+
+func (s *MemoryStorage) CallsByOp(op string) []StorageCall {
+	var filtered []StorageCall
+	for _, call := range s.Calls() {
+		if call.Op == op {
+			filtered = append(filtered, call)
+		}
+	}
+	return filtered
+}
+
+func (s *MemoryStorage) Object(path string) ([]byte, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[memoryKey(path)]
+	if !ok {
+		return nil, "", false
+	}
+	data := make([]byte, len(obj.data))
+	copy(data, obj.data)
+	return data, obj.opts.ContentType, true
+}
+
+func (s *MemoryStorage) Paths() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	paths := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		paths = append(paths, key)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (s *MemoryStorage) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects = make(map[string]memoryObject)
+	s.resetCalls()
+}
+
+// ResetCalls mengosongkan log panggilan tanpa menghapus objek.
+func (s *MemoryStorage) ResetCalls() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resetCalls()
+}
+
+func (s *MemoryStorage) resetCalls() {
+	s.calls = nil
+	s.next = 0
+	s.stats = make(map[string]CallStat)
+}
+
+// AsMemory mencari MemoryStorage di balik wrapper timeout, rate limit dan
+// prefix.
+func AsMemory(s Storage) (*MemoryStorage, bool) {
+	for {
+		switch current := s.(type) {
+		case *MemoryStorage:
+			return current, true
+		case interface{ Unwrap() Storage }:
+			s = current.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package adapter
+
+import (
+	"bytes"
+	"chrononews-scheduler/internal/config"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryStorageCallLog(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+
+	if err := storage.Put(ctx, "post_picture/a.webp", strings.NewReader("webp!"), PutOptions{ContentType: "image/webp"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	reader, err := storage.Open(ctx, "post_picture/a.webp")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	reader.Close()
+	if _, err := storage.Open(ctx, "post_picture/missing.jpg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Open path kosong: err = %v, want fs.ErrNotExist", err)
+	}
+	if err := storage.Delete(ctx, "post_picture/a.webp"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	want := []StorageCall{
+		{Op: OpPut, Path: "post_picture/a.webp", Size: 5, ContentType: "image/webp"},
+		{Op: OpOpen, Path: "post_picture/a.webp", Size: 5, ContentType: "image/webp"},
+		{Op: OpOpen, Path: "post_picture/missing.jpg"},
+		{Op: OpDelete, Path: "post_picture/a.webp", Size: 5},
+	}
+	calls := storage.Calls()
+	if len(calls) != len(want) {
+		t.Fatalf("jumlah panggilan = %d, want %d", len(calls), len(want))
+	}
+	for i, call := range calls {
+		if call.Op != want[i].Op || call.Path != want[i].Path || call.Size != want[i].Size || call.ContentType != want[i].ContentType {
+			t.Errorf("panggilan %d = {%s %s %d %q}, want {%s %s %d %q}", i,
+				call.Op, call.Path, call.Size, call.ContentType,
+				want[i].Op, want[i].Path, want[i].Size, want[i].ContentType)
+		}
+	}
+	if calls[2].Err == nil {
+		t.Errorf("Open path kosong tidak mencatat error")
+	}
+
+	if stat := storage.CallStats(OpOpen); stat.Count != 2 || stat.Bytes != 5 {
+		t.Errorf("CallStats(open) = %+v, want {Count:2 Bytes:5}", stat)
+	}
+	if _, _, ok := storage.Object("post_picture/a.webp"); ok {
+		t.Errorf("objek masih ada setelah Delete")
+	}
+}
+
+func TestMemoryStorageCallLogKeepsNewest(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+
+	for i := 0; i < memoryCallLogSize+3; i++ {
+		storage.Delete(ctx, "x")
+	}
+	storage.Delete(ctx, "last")
+
+	calls := storage.Calls()
+	if len(calls) != memoryCallLogSize {
+		t.Fatalf("jumlah panggilan = %d, want %d", len(calls), memoryCallLogSize)
+	}
+	if calls[len(calls)-1].Path != "last" {
+		t.Errorf("panggilan terakhir = %s, want last", calls[len(calls)-1].Path)
+	}
+	if stat := storage.CallStats(OpDelete); stat.Count != memoryCallLogSize+4 {
+		t.Errorf("CallStats(delete).Count = %d, want %d", stat.Count, memoryCallLogSize+4)
+	}
+}
+
+func TestMemoryStorageFallbackReads(t *testing.T) {
+	ctx := context.Background()
+	fallback := NewMemoryStorage(nil)
+	fallback.Put(ctx, "post_picture/a.jpg", strings.NewReader("jpeg"), PutOptions{ContentType: "image/jpeg"})
+	storage := NewMemoryStorage(fallback)
+
+	reader, err := storage.Open(ctx, "post_picture/a.jpg")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "jpeg" {
+		t.Errorf("isi = %q, want jpeg", data)
+	}
+	if calls := storage.CallsByOp(OpOpen); len(calls) != 1 || !calls[0].Fallback {
+		t.Errorf("Open tidak tercatat sebagai fallback: %+v", calls)
+	}
+
+	if err := storage.Put(ctx, "post_picture/a.webp", strings.NewReader("webp"), PutOptions{ContentType: "image/webp"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	storage.Delete(ctx, "post_picture/a.jpg")
+	if _, _, ok := fallback.Object("post_picture/a.webp"); ok {
+		t.Errorf("Put menulis ke fallback")
+	}
+	if _, _, ok := fallback.Object("post_picture/a.jpg"); !ok {
+		t.Errorf("Delete menghapus dari fallback")
+	}
+}
+
+func TestNewStorageMemoryWithLocalFallback(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "a.jpg")
+	if err := os.WriteFile(source, []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storage, err := NewStorage(&config.Config{
+		StorageMode:               "memory",
+		MemoryFallbackStorageMode: "local",
+		DirAttachment:             dir,
+		StorageReadRPS:            1000,
+		StorageGetTimeout:         time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	memory, ok := AsMemory(storage)
+	if !ok {
+		t.Fatalf("AsMemory tidak menemukan MemoryStorage di balik %T", storage)
+	}
+	if _, isLocal := memory.fallback.(*LocalStorage); !isLocal {
+		t.Errorf("fallback = %T, want *LocalStorage tanpa wrapper", memory.fallback)
+	}
+
+	reader, err := storage.Open(context.Background(), source)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	if !bytes.Equal(data, []byte("jpeg")) {
+		t.Errorf("isi = %q, want jpeg", data)
+	}
+}
+
+func TestAsMemoryThroughPrefix(t *testing.T) {
+	memory := NewMemoryStorage(nil)
+	storage := WithPrefix(memory, "mirror")
+
+	found, ok := AsMemory(storage)
+	if !ok || found != memory {
+		t.Fatalf("AsMemory = %p, %v; want %p", found, ok, memory)
+	}
+	if _, ok := AsMemory(NewLocalStorage()); ok {
+		t.Errorf("AsMemory menemukan MemoryStorage di LocalStorage")
+	}
+}
@@ -0,0 +1,230 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+const (
+	MirrorPolicyStrict     = "strict"
+	MirrorPolicyBestEffort = "best-effort"
+)
+
+// MirrorOptions mengatur kebijakan tulis mirror. Pada strict, Put gagal bila
+// salah satu backend gagal dan objek di primary ikut dihapus bila Put tersebut
+// yang membuatnya; objek yang tertimpa dilaporkan ke OnMirrorFailure. Pada
+// best-effort,
+// kegagalan Put, Delete dan Move di mirror dilaporkan lewat OnMirrorFailure
+// agar bisa diperbaiki job repair.
+type MirrorOptions struct {
+	Policy          string
+	OnMirrorFailure func(path string, err error)
+}
+
+// MirrorStorage mereplikasi setiap Put dan Delete ke backend kedua. Stream
+// input dibaca sekali dan dialirkan ke kedua backend secara bersamaan; baca
+// dan List hanya dilayani primary.
+type MirrorStorage struct {
+	primary   Storage
+	mirror    Storage
+	policy    string
+	onFailure func(path string, err error)
+}
+
+func NewMirrorStorage(primary, mirror Storage, opts MirrorOptions) *MirrorStorage {
+	if opts.Policy == "" {
+		opts.Policy = MirrorPolicyBestEffort
+	}
+	return &MirrorStorage{
+		primary:   primary,
+		mirror:    mirror,
+		policy:    opts.Policy,
+		onFailure: opts.OnMirrorFailure,
+	}
+}
+
+func (s *MirrorStorage) Primary() Storage {
+	return s.primary
+}
+
+func (s *MirrorStorage) Mirror() Storage {
+	return s.mirror
+}
+
+// mirrorTee meneruskan data ke pipe mirror tanpa pernah menggagalkan pembaca
+// utama; error pertama disimpan dan tulisan berikutnya dibuang.
+type mirrorTee struct {
+	w   *io.PipeWriter
+	err error
+}
+
+func (t *mirrorTee) Write(p []byte) (int, error) {
+	if t.err == nil {
+		if _, err := t.w.Write(p); err != nil {
+			t.err = err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *MirrorStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	// Rollback strict hanya boleh menghapus objek yang dibuat Put ini, bukan
+	// versi lama yang tertimpa.
+	var existed bool
+	if s.policy == MirrorPolicyStrict {
+		var err error
+		if existed, err = s.primary.Exists(ctx, path); err != nil {
+			return err
+		}
+	}
+
+	pr, pw := io.Pipe()
+	mirrorDone := make(chan error, 1)
+	go func() {
+		err := s.mirror.Put(ctx, path, pr, opts)
+		pr.CloseWithError(err)
+		mirrorDone <- err
+	}()
+
+	tee := &mirrorTee{w: pw}
+	err := s.primary.Put(ctx, path, io.TeeReader(reader, tee), opts)
+	pw.CloseWithError(err)
+	mirrorErr := <-mirrorDone
+
+	if err != nil {
+		return err
+	}
+	if mirrorErr == nil {
+		return nil
+	}
+
+	if s.policy == MirrorPolicyStrict {
+		if !existed {
+			if delErr := s.primary.Delete(context.WithoutCancel(ctx), path); delErr != nil {
+				slog.Warn("Gagal rollback objek primary setelah mirror gagal", "path", path, "error", delErr)
+			}
+		} else {
+			slog.Warn("Mirror gagal setelah objek primary tertimpa, objek dijadwalkan untuk repair", "path", path, "error", mirrorErr)
+			if s.onFailure != nil {
+				s.onFailure(path, mirrorErr)
+			}
+		}
+		return fmt.Errorf("gagal menulis ke mirror: %w", mirrorErr)
+	}
+
+	slog.Warn("Gagal menulis ke mirror, objek dijadwalkan untuk repair", "path", path, "error", mirrorErr)
+	if s.onFailure != nil {
+		s.onFailure(path, mirrorErr)
+	}
+	return nil
+}
+
+func (s *MirrorStorage) Delete(ctx context.Context, path string) error {
+	err := s.primary.Delete(ctx, path)
+	mirrorErr := s.mirror.Delete(ctx, path)
+	if mirrorErr == nil {
+		return err
+	}
+	if s.policy == MirrorPolicyStrict {
+		return errors.Join(err, mirrorErr)
+	}
+	s.reportDeleteFailure(path, mirrorErr)
+	return err
+}
+
+func (s *MirrorStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	results := s.primary.DeleteMany(ctx, paths)
+	for i, mirrored := range s.mirror.DeleteMany(ctx, paths) {
+		if mirrored.Err == nil {
+			continue
+		}
+		if s.policy == MirrorPolicyStrict {
+			results[i].Err = errors.Join(results[i].Err, mirrored.Err)
+			continue
+		}
+		s.reportDeleteFailure(mirrored.Path, mirrored.Err)
+	}
+	return results
+}
+
+// reportDeleteFailure menjadwalkan penghapusan ulang di mirror. Job repair
+// menghapus objek mirror yang sudah tidak ada di primary.
+func (s *MirrorStorage) reportDeleteFailure(path string, err error) {
+	slog.Warn("Gagal menghapus objek di mirror, objek dijadwalkan untuk repair", "path", path, "error", err)
+	if s.onFailure != nil {
+		s.onFailure(path, err)
+	}
+}
+
+func (s *MirrorStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.primary.Open(ctx, path)
+}
+
+func (s *MirrorStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	return s.primary.Stat(ctx, path)
+}
+
+func (s *MirrorStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return s.primary.Exists(ctx, path)
+}
+
+func (s *MirrorStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	return s.primary.List(ctx, opts)
+}
+
+// SetStorageClass diterapkan ke kedua backend. Mirror yang tidak mendukung
+// storage class (misalnya lokal) dilewati.
+func (s *MirrorStorage) SetStorageClass(ctx context.Context, path, class string) error {
+	if err := setStorageClass(ctx, s.primary, path, class); err != nil {
+		return err
+	}
+	mirrorErr := setStorageClass(ctx, s.mirror, path, class)
+	if mirrorErr == nil || errors.Is(mirrorErr, errors.ErrUnsupported) {
+		return nil
+	}
+	if s.policy == MirrorPolicyStrict {
+		return fmt.Errorf("gagal mengubah storage class di mirror: %w", mirrorErr)
+	}
+	slog.Warn("Gagal mengubah storage class di mirror", "path", path, "class", class, "error", mirrorErr)
+	return nil
+}
+
+func (s *MirrorStorage) Move(ctx context.Context, from, to string) error {
+	if err := Move(ctx, s.primary, from, to); err != nil {
+		return err
+	}
+	mirrorErr := Move(ctx, s.mirror, from, to)
+	if mirrorErr == nil {
+		return nil
+	}
+	if s.policy == MirrorPolicyStrict {
+		return fmt.Errorf("gagal memindahkan objek di mirror: %w", mirrorErr)
+	}
+	slog.Warn("Gagal memindahkan objek di mirror, objek dijadwalkan untuk repair", "from", from, "to", to, "error", mirrorErr)
+	if s.onFailure != nil {
+		s.onFailure(from, mirrorErr)
+		s.onFailure(to, mirrorErr)
+	}
+	return nil
+}
+
+func (s *MirrorStorage) Copy(ctx context.Context, from, to string) error {
+	if err := Copy(ctx, s.primary, from, to); err != nil {
+		return err
+	}
+	mirrorErr := Copy(ctx, s.mirror, from, to)
+	if mirrorErr == nil {
+		return nil
+	}
+	if s.policy == MirrorPolicyStrict {
+		return fmt.Errorf("gagal menyalin objek di mirror: %w", mirrorErr)
+	}
+	slog.Warn("Gagal menyalin objek di mirror, objek dijadwalkan untuk repair", "from", from, "to", to, "error", mirrorErr)
+	if s.onFailure != nil {
+		s.onFailure(to, mirrorErr)
+	}
+	return nil
+}
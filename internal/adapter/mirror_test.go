@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// failingPutStorage menggagalkan setiap Put setelah menguras reader-nya.
+type failingPutStorage struct {
+	Storage
+}
+
+func (s failingPutStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	io.Copy(io.Discard, reader)
+	return errors.New("mirror tidak tersedia")
+}
+
+func TestMirrorStorageStrictRollback(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   string
+		wantObject string
+		wantRepair bool
+	}{
+		{name: "objek baru dihapus"},
+		{name: "objek tertimpa dipertahankan", existing: "lama", wantObject: "baru", wantRepair: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			primary := NewMemoryStorage(nil)
+			if tt.existing != "" {
+				primary.Put(ctx, "a.webp", strings.NewReader(tt.existing), PutOptions{})
+			}
+			var repaired []string
+			storage := NewMirrorStorage(primary, failingPutStorage{NewMemoryStorage(nil)}, MirrorOptions{
+				Policy:          MirrorPolicyStrict,
+				OnMirrorFailure: func(path string, err error) { repaired = append(repaired, path) },
+			})
+
+			if err := storage.Put(ctx, "a.webp", strings.NewReader("baru"), PutOptions{}); err == nil {
+				t.Fatalf("Put strict tidak mengembalikan error saat mirror gagal")
+			}
+
+			data, _, ok := primary.Object("a.webp")
+			if string(data) != tt.wantObject || ok != (tt.wantObject != "") {
+				t.Errorf("objek primary = %q (ada %v), want %q", data, ok, tt.wantObject)
+			}
+			if (len(repaired) > 0) != tt.wantRepair {
+				t.Errorf("repair = %v, want dijadwalkan %v", repaired, tt.wantRepair)
+			}
+		})
+	}
+}
+
+func TestMirrorStorageBestEffortKeepsUpload(t *testing.T) {
+	ctx := context.Background()
+	primary := NewMemoryStorage(nil)
+	var repaired []string
+	storage := NewMirrorStorage(primary, failingPutStorage{NewMemoryStorage(nil)}, MirrorOptions{
+		OnMirrorFailure: func(path string, err error) { repaired = append(repaired, path) },
+	})
+
+	if err := storage.Put(ctx, "a.webp", strings.NewReader("baru"), PutOptions{}); err != nil {
+		t.Fatalf("Put best-effort: %v", err)
+	}
+	if _, _, ok := primary.Object("a.webp"); !ok {
+		t.Errorf("objek primary hilang")
+	}
+	if len(repaired) != 1 || repaired[0] != "a.webp" {
+		t.Errorf("repair = %v, want [a.webp]", repaired)
+	}
+}
@@ -0,0 +1,68 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+)
+
+// Mover diimplementasikan backend yang bisa memindahkan objek tanpa
+// mengalirkan isinya lewat scheduler (rename lokal, CopyObject S3).
+type Mover interface {
+	Move(ctx context.Context, from, to string) error
+}
+
+// Move memindahkan objek beserta header dan metadata-nya. Backend yang tidak
+// mengimplementasikan Mover dipindahkan dengan salin lalu hapus, sehingga
+// wrapper seperti mirror dan tiering tetap menulis ke semua backend-nya.
+func Move(ctx context.Context, s Storage, from, to string) error {
+	if mover, ok := s.(Mover); ok {
+		return mover.Move(ctx, from, to)
+	}
+	return copyThenDelete(ctx, s, from, to)
+}
+
+// Copier diimplementasikan backend yang bisa menyalin objek di sisi server,
+// misalnya CopyObject S3.
+type Copier interface {
+	Copy(ctx context.Context, from, to string) error
+}
+
+// Copy menyalin objek beserta header dan metadata-nya tanpa menghapus
+// sumbernya. Backend yang tidak mengimplementasikan Copier disalin lewat
+// Open lalu Put.
+func Copy(ctx context.Context, s Storage, from, to string) error {
+	if copier, ok := s.(Copier); ok {
+		return copier.Copy(ctx, from, to)
+	}
+	return copyWithin(ctx, s, from, to)
+}
+
+func copyThenDelete(ctx context.Context, s Storage, from, to string) error {
+	if err := copyWithin(ctx, s, from, to); err != nil {
+		return err
+	}
+	return s.Delete(ctx, from)
+}
+
+func copyWithin(ctx context.Context, s Storage, from, to string) error {
+	info, err := s.Stat(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.Open(ctx, from)
+	if err != nil {
+		return err
+	}
+	err = s.Put(ctx, to, reader, PutOptions{
+		ContentType:        info.ContentType,
+		CacheControl:       info.CacheControl,
+		ContentDisposition: info.ContentDisposition,
+		Metadata:           info.Metadata,
+	})
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("gagal menyalin %s ke %s: %w", from, to, err)
+	}
+	return nil
+}
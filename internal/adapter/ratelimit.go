@@ -0,0 +1,294 @@
+package adapter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimits membatasi laju trafik storage per backend. Nilai 0 berarti tanpa
+// batas. Stat, Exists dan List dihitung sebagai request baca; SetStorageClass,
+// Move dan Copy sebagai request tulis.
+type RateLimits struct {
+	ReadRequests   int
+	ReadBytes      int
+	WriteRequests  int
+	WriteBytes     int
+	DeleteRequests int
+}
+
+const (
+	rateClassRead   = "read"
+	rateClassWrite  = "write"
+	rateClassDelete = "delete"
+)
+
+// tokenBucket mengisi ulang token sebesar rate per detik sampai burst.
+// Token boleh minus: pemanggil memesan token lalu menunggu sampai saldo
+// kembali nol, sehingga antrean tetap adil tanpa goroutine pengisi.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(perSecond int) *tokenBucket {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   float64(perSecond),
+		burst:  float64(perSecond),
+		tokens: float64(perSecond),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) cancel(n float64) {
+	b.mu.Lock()
+	b.tokens = math.Min(b.burst, b.tokens+n)
+	b.mu.Unlock()
+}
+
+// wait mengambil n token, dipecah per burst agar permintaan yang lebih besar
+// dari kapasitas bucket tetap bisa dilayani.
+func (b *tokenBucket) wait(ctx context.Context, n int) (time.Duration, error) {
+	if b == nil || n <= 0 {
+		return 0, ctx.Err()
+	}
+
+	var waited time.Duration
+	remaining := float64(n)
+	for remaining > 0 {
+		take := math.Min(remaining, b.burst)
+		delay := b.reserve(take)
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				b.cancel(take)
+				return waited, ctx.Err()
+			case <-timer.C:
+			}
+			waited += delay
+		}
+		remaining -= take
+	}
+	return waited, nil
+}
+
+type rateStat struct {
+	waits    atomic.Int64
+	waitTime atomic.Int64
+}
+
+var rateStats = map[string]*rateStat{
+	rateClassRead:   {},
+	rateClassWrite:  {},
+	rateClassDelete: {},
+}
+
+func recordRateWait(class, op, path string, waited time.Duration) {
+	if waited <= 0 {
+		return
+	}
+	stat := rateStats[class]
+	stat.waits.Add(1)
+	stat.waitTime.Add(int64(waited))
+	slog.Debug("Operasi storage menunggu rate limiter", "op", op, "path", path, "wait", waited.String())
+}
+
+// LogRateLimitWaits mencatat total waktu tunggu rate limiter per kelas
+// operasi sejak pemanggilan sebelumnya lalu mereset penghitungnya.
+func LogRateLimitWaits() {
+	for _, class := range []string{rateClassRead, rateClassWrite, rateClassDelete} {
+		stat := rateStats[class]
+		waits := stat.waits.Swap(0)
+		waitTime := time.Duration(stat.waitTime.Swap(0))
+		if waits == 0 {
+			continue
+		}
+		slog.Info("Ringkasan rate limiter storage",
+			"class", class,
+			"tertunda", waits,
+			"total_wait", waitTime.String(),
+			"avg_wait", (waitTime / time.Duration(waits)).String(),
+		)
+	}
+}
+
+type rateLimitedStorage struct {
+	next           Storage
+	readRequests   *tokenBucket
+	readBytes      *tokenBucket
+	writeRequests  *tokenBucket
+	writeBytes     *tokenBucket
+	deleteRequests *tokenBucket
+}
+
+func WithRateLimits(next Storage, limits RateLimits) Storage {
+	if limits.ReadRequests <= 0 && limits.ReadBytes <= 0 && limits.WriteRequests <= 0 &&
+		limits.WriteBytes <= 0 && limits.DeleteRequests <= 0 {
+		return next
+	}
+	return &rateLimitedStorage{
+		next:           next,
+		readRequests:   newTokenBucket(limits.ReadRequests),
+		readBytes:      newTokenBucket(limits.ReadBytes),
+		writeRequests:  newTokenBucket(limits.WriteRequests),
+		writeBytes:     newTokenBucket(limits.WriteBytes),
+		deleteRequests: newTokenBucket(limits.DeleteRequests),
+	}
+}
+
+func (s *rateLimitedStorage) waitRequest(ctx context.Context, bucket *tokenBucket, class, op, path string, n int) error {
+	waited, err := bucket.wait(ctx, n)
+	recordRateWait(class, op, path, waited)
+	return err
+}
+
+// throttledReader menahan setiap Read sampai token byte tersedia. Ukuran
+// buffer dibatasi burst agar satu Read tidak melebihi kapasitas bucket.
+type throttledReader struct {
+	ctx    context.Context
+	reader io.Reader
+	bucket *tokenBucket
+	class  string
+	op     string
+	path   string
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if limit := int(r.bucket.burst); len(p) > limit {
+		p = p[:limit]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		waited, waitErr := r.bucket.wait(r.ctx, n)
+		recordRateWait(r.class, r.op, r.path, waited)
+		if waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+type throttledReadCloser struct {
+	throttledReader
+	closer io.Closer
+}
+
+func (r *throttledReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+func (s *rateLimitedStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := s.waitRequest(ctx, s.readRequests, rateClassRead, "Open", path, 1); err != nil {
+		return nil, err
+	}
+	reader, err := s.next.Open(ctx, path)
+	if err != nil || s.readBytes == nil {
+		return reader, err
+	}
+	return &throttledReadCloser{
+		throttledReader: throttledReader{ctx: ctx, reader: reader, bucket: s.readBytes, class: rateClassRead, op: "Open", path: path},
+		closer:          reader,
+	}, nil
+}
+
+func (s *rateLimitedStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	if err := s.waitRequest(ctx, s.writeRequests, rateClassWrite, "Put", path, 1); err != nil {
+		return err
+	}
+	if s.writeBytes != nil {
+		reader = &throttledReader{ctx: ctx, reader: reader, bucket: s.writeBytes, class: rateClassWrite, op: "Put", path: path}
+	}
+	return s.next.Put(ctx, path, reader, opts)
+}
+
+func (s *rateLimitedStorage) Delete(ctx context.Context, path string) error {
+	if err := s.waitRequest(ctx, s.deleteRequests, rateClassDelete, "Delete", path, 1); err != nil {
+		return err
+	}
+	return s.next.Delete(ctx, path)
+}
+
+// DeleteMany dihitung satu request per batch DeleteObjects (1000 key).
+func (s *rateLimitedStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	batches := (len(paths) + maxDeleteObjectsKeys - 1) / maxDeleteObjectsKeys
+	if err := s.waitRequest(ctx, s.deleteRequests, rateClassDelete, "DeleteMany", "", batches); err != nil {
+		results := make([]DeleteResult, len(paths))
+		for i, path := range paths {
+			results[i] = DeleteResult{Path: path, Err: err}
+		}
+		return results
+	}
+	return s.next.DeleteMany(ctx, paths)
+}
+
+func (s *rateLimitedStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	if err := s.waitRequest(ctx, s.readRequests, rateClassRead, "Stat", path, 1); err != nil {
+		return ObjectInfo{}, err
+	}
+	return s.next.Stat(ctx, path)
+}
+
+func (s *rateLimitedStorage) Exists(ctx context.Context, path string) (bool, error) {
+	if err := s.waitRequest(ctx, s.readRequests, rateClassRead, "Exists", path, 1); err != nil {
+		return false, err
+	}
+	return s.next.Exists(ctx, path)
+}
+
+func (s *rateLimitedStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	if err := s.waitRequest(ctx, s.readRequests, rateClassRead, "List", opts.Prefix, 1); err != nil {
+		return ListPage{}, err
+	}
+	return s.next.List(ctx, opts)
+}
+
+func (s *rateLimitedStorage) SetStorageClass(ctx context.Context, path, class string) error {
+	if err := s.waitRequest(ctx, s.writeRequests, rateClassWrite, "SetStorageClass", path, 1); err != nil {
+		return err
+	}
+	return setStorageClass(ctx, s.next, path, class)
+}
+
+func (s *rateLimitedStorage) Move(ctx context.Context, from, to string) error {
+	if err := s.waitRequest(ctx, s.writeRequests, rateClassWrite, "Move", from, 1); err != nil {
+		return err
+	}
+	return Move(ctx, s.next, from, to)
+}
+
+func (s *rateLimitedStorage) Unwrap() Storage {
+	return s.next
+}
+
+func (s *rateLimitedStorage) Copy(ctx context.Context, from, to string) error {
+	if err := s.waitRequest(ctx, s.writeRequests, rateClassWrite, "Copy", from, 1); err != nil {
+		return err
+	}
+	return Copy(ctx, s.next, from, to)
+}
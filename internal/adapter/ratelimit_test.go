@@ -0,0 +1,106 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	bucket := newTokenBucket(10)
+
+	if delay := bucket.reserve(10); delay != 0 {
+		t.Errorf("reserve dalam burst = %v, want 0", delay)
+	}
+	delay := bucket.reserve(5)
+	if delay < 450*time.Millisecond || delay > 500*time.Millisecond {
+		t.Errorf("reserve melebihi saldo = %v, want sekitar 500ms", delay)
+	}
+	if newTokenBucket(0) != nil {
+		t.Errorf("newTokenBucket(0) bukan nil")
+	}
+}
+
+func TestTokenBucketWaitSplitsLargeRequests(t *testing.T) {
+	bucket := newTokenBucket(100000)
+
+	waited, err := bucket.wait(context.Background(), 105000)
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if waited < 40*time.Millisecond || waited > 60*time.Millisecond {
+		t.Errorf("waited = %v, want sekitar 50ms untuk 5000 token di atas burst", waited)
+	}
+
+	var nilBucket *tokenBucket
+	if waited, err := nilBucket.wait(context.Background(), 100); waited != 0 || err != nil {
+		t.Errorf("wait pada bucket nil = %v, %v; want 0, nil", waited, err)
+	}
+}
+
+func TestTokenBucketWaitReturnsTokensOnCancel(t *testing.T) {
+	bucket := newTokenBucket(10)
+	bucket.reserve(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := bucket.wait(ctx, 5); err == nil {
+		t.Fatalf("wait dengan context batal tidak mengembalikan error")
+	}
+
+	bucket.mu.Lock()
+	tokens := bucket.tokens
+	bucket.mu.Unlock()
+	if math.Abs(tokens) > 0.5 {
+		t.Errorf("saldo token = %.2f, want sekitar 0 setelah pesanan dibatalkan", tokens)
+	}
+}
+
+func TestThrottledReaderCapsReadsAtBurst(t *testing.T) {
+	reader := &throttledReader{
+		ctx:    context.Background(),
+		reader: strings.NewReader("0123456789"),
+		bucket: newTokenBucket(4),
+		class:  rateClassRead,
+	}
+
+	buf := make([]byte, 10)
+	n, err := reader.Read(buf)
+	if err != nil || n != 4 {
+		t.Errorf("Read = %d, %v; want 4 byte", n, err)
+	}
+}
+
+func TestRateLimitedStorageCountsDeleteBatches(t *testing.T) {
+	memory := NewMemoryStorage(nil)
+	storage := WithRateLimits(memory, RateLimits{DeleteRequests: 100, WriteBytes: 1 << 20}).(*rateLimitedStorage)
+
+	paths := make([]string, maxDeleteObjectsKeys+1)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("%d.webp", i)
+	}
+	storage.DeleteMany(context.Background(), paths)
+
+	storage.deleteRequests.mu.Lock()
+	tokens := storage.deleteRequests.tokens
+	storage.deleteRequests.mu.Unlock()
+	if tokens < 97.5 || tokens > 98.5 {
+		t.Errorf("saldo token delete = %.2f, want sekitar 98 (2 batch)", tokens)
+	}
+
+	data := bytes.Repeat([]byte("x"), 1000)
+	if err := storage.Put(context.Background(), "a.webp", bytes.NewReader(data), PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	stored, _, _ := memory.Object("a.webp")
+	if !bytes.Equal(stored, data) {
+		t.Errorf("isi objek berubah setelah melewati throttledReader")
+	}
+	if _, ok := WithRateLimits(memory, RateLimits{}).(*MemoryStorage); !ok {
+		t.Errorf("WithRateLimits tanpa batas tetap membungkus storage")
+	}
+}
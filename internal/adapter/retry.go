@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// RetryPolicy mengatur retry untuk error sementara dari storage (throttling,
+// 5xx, timeout, koneksi terputus). MaxElapsed membatasi total waktu termasuk
+// jeda antar percobaan; 0 berarti hanya dibatasi MaxAttempts dan context.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxElapsed     time.Duration
+}
+
+var retryableErrors = retry.IsErrorRetryables(append([]retry.IsErrorRetryable{
+	retry.RetryableHTTPStatusCode{Codes: map[int]struct{}{429: {}}},
+}, retry.DefaultRetryables...))
+
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	return retryableErrors.IsErrorRetryable(err) == aws.TrueTernary
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.InitialBackoff <= 0 {
+		return 0
+	}
+	delay := p.InitialBackoff << (attempt - 1)
+	if delay <= 0 || (p.MaxBackoff > 0 && delay > p.MaxBackoff) {
+		delay = p.MaxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+func (p RetryPolicy) Do(ctx context.Context, op, key string, fn func(ctx context.Context) error) error {
+	start := time.Now()
+
+	attempt := 1
+	for ; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				slog.Info("Operasi storage berhasil setelah retry", "op", op, "key", key, "retries", attempt-1)
+			}
+			return nil
+		}
+
+		if attempt >= p.MaxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return retryError(op, key, attempt, err)
+		}
+
+		delay := p.backoff(attempt)
+		if p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed {
+			slog.Warn("Budget waktu retry storage habis", "op", op, "key", key, "retries", attempt-1, "elapsed", time.Since(start).String())
+			return retryError(op, key, attempt, err)
+		}
+
+		slog.Warn("Operasi storage gagal sementara, mencoba ulang",
+			"op", op,
+			"key", key,
+			"attempt", attempt,
+			"delay", delay.String(),
+			"error", err,
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retryError(op, key, attempt, err)
+		case <-timer.C:
+		}
+	}
+}
+
+func retryError(op, key string, attempts int, err error) error {
+	if attempts <= 1 {
+		return err
+	}
+	slog.Error("Operasi storage gagal setelah retry", "op", op, "key", key, "retries", attempts-1, "error", err)
+	return fmt.Errorf("%s %s gagal setelah %d percobaan: %w", op, key, attempts, err)
+}
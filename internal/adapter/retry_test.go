@@ -0,0 +1,121 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func TestRetryBackoffBounds(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, ceiling := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		70: time.Second,
+	} {
+		for i := 0; i < 50; i++ {
+			delay := policy.backoff(attempt)
+			if delay < ceiling/2 || delay > ceiling {
+				t.Fatalf("backoff(%d) = %v, want antara %v dan %v", attempt, delay, ceiling/2, ceiling)
+			}
+		}
+	}
+	if delay := (RetryPolicy{}).backoff(3); delay != 0 {
+		t.Errorf("backoff tanpa InitialBackoff = %v, want 0", delay)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil},
+		{err: statusError(http.StatusServiceUnavailable), want: true},
+		{err: statusError(http.StatusTooManyRequests), want: true},
+		{err: statusError(http.StatusForbidden)},
+		{err: context.Canceled},
+		{err: fmt.Errorf("a.webp: %w", fs.ErrNotExist)},
+		{err: ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "berhasil setelah retry", errs: []error{statusError(503), statusError(500)}, wantCalls: 3},
+		{name: "batas percobaan", errs: []error{statusError(503), statusError(503), statusError(503), nil}, wantCalls: 3, wantErr: true},
+		{name: "error permanen", errs: []error{statusError(403)}, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := policy.Do(context.Background(), "GetObject", "a.webp", func(context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+				t.Errorf("calls = %d, err = %v; want calls %d, error %v", calls, err, tt.wantCalls, tt.wantErr)
+			}
+			if err != nil && calls > 1 && !errors.Is(err, tt.errs[0]) {
+				t.Errorf("error akhir tidak membungkus error asli: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoStopsAtMaxElapsed(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour, MaxElapsed: time.Second}
+
+	calls := 0
+	start := time.Now()
+	err := policy.Do(context.Background(), "PutObject", "a.webp", func(context.Context) error {
+		calls++
+		return statusError(503)
+	})
+	if err == nil || calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("calls = %d, err = %v setelah %v; want berhenti sebelum menunggu backoff", calls, err, time.Since(start))
+	}
+}
+
+func TestS3StorageRetriesTransientErrors(t *testing.T) {
+	fake, client := newFakeS3(t)
+	storage := NewS3Storage(client, S3Options{
+		Bucket: fakeBucket,
+		Retry:  RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	fake.failures = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+
+	if err := storage.Put(context.Background(), "a.webp", strings.NewReader("webp"), PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if string(fake.objects["a.webp"]) != "webp" {
+		t.Errorf("objek = %q, want webp", fake.objects["a.webp"])
+	}
+}
@@ -0,0 +1,396 @@
+package adapter
+
+import (
+	"bytes"
+	"chrononews-scheduler/internal/config"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+func init() {
+	Register("s3", func(cfg *config.Config) (Storage, error) {
+		client, err := NewS3Client(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3Storage(client, S3Options{
+			Bucket:             cfg.S3Bucket,
+			MultipartThreshold: int64(cfg.S3MultipartThresholdMB) * 1024 * 1024,
+			PartSize:           int64(cfg.S3MultipartPartSizeMB) * 1024 * 1024,
+			Retry: RetryPolicy{
+				MaxAttempts:    cfg.StorageRetryMaxAttempts,
+				InitialBackoff: cfg.StorageRetryInitialBackoff,
+				MaxBackoff:     cfg.StorageRetryMaxBackoff,
+				MaxElapsed:     cfg.StorageRetryMaxElapsed,
+			},
+			VerifyChecksums: cfg.StorageVerifyUploads,
+		}), nil
+	})
+}
+
+func NewS3Client(cfg *config.Config) (*s3.Client, error) {
+	slog.Info("Menginisialisasi AWS S3 Client...")
+
+	awsCfg, err := awsConfig.LoadDefaultConfig(context.TODO(),
+		awsConfig.WithRegion(cfg.S3Region),
+		awsConfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			cfg.S3AccessKey,
+			cfg.S3SecretKey,
+			"",
+		)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gagal load config AWS: %w", err)
+	}
+
+	// Retry ditangani RetryPolicy di S3Storage agar tidak berlipat dengan retryer bawaan SDK.
+	disableSDKRetry := func(o *s3.Options) {
+		o.RetryMaxAttempts = 1
+	}
+
+	if cfg.S3Endpoint != "" {
+		return s3.NewFromConfig(awsCfg, disableSDKRetry, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.S3Endpoint)
+			o.UsePathStyle = true
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+			o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+		}), nil
+	}
+	return s3.NewFromConfig(awsCfg, disableSDKRetry), nil
+}
+
+const (
+	minMultipartPartSize      = 5 * 1024 * 1024
+	defaultMultipartThreshold = 8 * 1024 * 1024
+)
+
+// S3Options.VerifyChecksums mengirim Content-MD5 di setiap PutObject dan
+// UploadPart lalu mencocokkan ETag hasil upload dengan MD5 yang dihitung.
+type S3Options struct {
+	Bucket             string
+	MultipartThreshold int64
+	PartSize           int64
+	Retry              RetryPolicy
+	VerifyChecksums    bool
+}
+
+type S3Storage struct {
+	client             *s3.Client
+	bucket             string
+	multipartThreshold int64
+	partSize           int64
+	retry              RetryPolicy
+	verify             bool
+}
+
+func NewS3Storage(client *s3.Client, opts S3Options) *S3Storage {
+	if opts.MultipartThreshold <= 0 {
+		opts.MultipartThreshold = defaultMultipartThreshold
+	}
+	if opts.PartSize < minMultipartPartSize {
+		opts.PartSize = minMultipartPartSize
+	}
+	return &S3Storage{
+		client:             client,
+		bucket:             opts.Bucket,
+		multipartThreshold: opts.MultipartThreshold,
+		partSize:           opts.PartSize,
+		retry:              opts.Retry,
+		verify:             opts.VerifyChecksums,
+	}
+}
+
+func (s *S3Storage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("s3 client is not initialized")
+	}
+	key := filepath.ToSlash(path)
+
+	var output *s3.GetObjectOutput
+	err := s.retry.Do(ctx, "GetObject", key, func(ctx context.Context) error {
+		var err error
+		output, err = s.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		return mapS3Error(key, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	if s.client == nil {
+		return fmt.Errorf("s3 client is not initialized")
+	}
+	key := filepath.ToSlash(path)
+
+	var head bytes.Buffer
+	n, err := io.CopyN(&head, reader, s.multipartThreshold)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n < s.multipartThreshold {
+		return s.putSingle(ctx, key, head.Bytes(), opts)
+	}
+
+	return s.putMultipart(ctx, key, io.MultiReader(&head, reader), opts)
+}
+
+func (s *S3Storage) putSingle(ctx context.Context, key string, body []byte, opts PutOptions) error {
+	var contentMD5 *string
+	var expectedETag string
+	if s.verify {
+		sum := md5.Sum(body)
+		contentMD5 = aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+		expectedETag = hex.EncodeToString(sum[:])
+	}
+
+	var output *s3.PutObjectOutput
+	err := s.retry.Do(ctx, "PutObject", key, func(ctx context.Context) error {
+		var err error
+		output, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:             aws.String(s.bucket),
+			Key:                aws.String(key),
+			Body:               bytes.NewReader(body),
+			ContentLength:      aws.Int64(int64(len(body))),
+			ContentMD5:         contentMD5,
+			ContentType:        optionalString(opts.ContentType),
+			CacheControl:       optionalString(opts.CacheControl),
+			ContentDisposition: optionalString(opts.ContentDisposition),
+			Metadata:           encodeS3Metadata(opts.Metadata),
+		})
+		return mapChecksumError(key, err)
+	})
+	if err != nil || !s.verify {
+		return err
+	}
+	return s.verifyUploaded(ctx, key, trimETag(output.ETag), expectedETag)
+}
+
+// verifyUploaded menghapus objek yang ETag-nya tidak cocok agar tidak ada
+// objek rusak yang tertinggal di bucket.
+func (s *S3Storage) verifyUploaded(ctx context.Context, key, etag, expected string) error {
+	err := verifyETag(key, etag, expected)
+	if err == nil {
+		return nil
+	}
+	if delErr := s.Delete(context.WithoutCancel(ctx), key); delErr != nil {
+		slog.Warn("Gagal menghapus objek dengan checksum tidak cocok", "key", key, "error", delErr)
+	}
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, path string) error {
+	if s.client == nil {
+		return fmt.Errorf("s3 client is not initialized")
+	}
+	key := filepath.ToSlash(path)
+	return s.retry.Do(ctx, "DeleteObject", key, func(ctx context.Context) error {
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+}
+
+func mapS3Error(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var noSuchKey *s3types.NoSuchKey
+	var notFound *s3types.NotFound
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return fmt.Errorf("s3 object %s: %w", key, fs.ErrNotExist)
+	}
+	// CopyObject melaporkan sumber yang hilang sebagai APIError generik.
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchKey" {
+		return fmt.Errorf("s3 object %s: %w", key, fs.ErrNotExist)
+	}
+	return err
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// encodeS3Metadata meng-escape nilai non-ASCII karena header x-amz-meta-*
+// hanya aman untuk US-ASCII.
+func encodeS3Metadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	encoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		encoded[strings.ToLower(key)] = url.PathEscape(value)
+	}
+	return encoded
+}
+
+func decodeS3Metadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	decoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		decoded[key] = value
+	}
+	return decoded
+}
+
+func trimETag(etag *string) string {
+	return strings.Trim(aws.ToString(etag), "\"")
+}
+
+func (s *S3Storage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	if s.client == nil {
+		return ObjectInfo{}, fmt.Errorf("s3 client is not initialized")
+	}
+	key := filepath.ToSlash(path)
+
+	var output *s3.HeadObjectOutput
+	err := s.retry.Do(ctx, "HeadObject", key, func(ctx context.Context) error {
+		var err error
+		output, err = s.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		return mapS3Error(key, err)
+	})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{
+		Path:               key,
+		Size:               aws.ToInt64(output.ContentLength),
+		ModTime:            aws.ToTime(output.LastModified),
+		ContentType:        aws.ToString(output.ContentType),
+		ETag:               trimETag(output.ETag),
+		CacheControl:       aws.ToString(output.CacheControl),
+		ContentDisposition: aws.ToString(output.ContentDisposition),
+		Metadata:           decodeS3Metadata(output.Metadata),
+	}, nil
+}
+
+func (s *S3Storage) Exists(ctx context.Context, path string) (bool, error) {
+	return existsFromStat(ctx, s, path)
+}
+
+func (s *S3Storage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	if s.client == nil {
+		return ListPage{}, fmt.Errorf("s3 client is not initialized")
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		MaxKeys: aws.Int32(int32(opts.limit())),
+	}
+	if prefix := normalizePrefix(opts.Prefix); prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if opts.ContinuationToken != "" {
+		input.ContinuationToken = aws.String(opts.ContinuationToken)
+	}
+
+	var output *s3.ListObjectsV2Output
+	err := s.retry.Do(ctx, "ListObjectsV2", aws.ToString(input.Prefix), func(ctx context.Context) error {
+		var err error
+		output, err = s.client.ListObjectsV2(ctx, input)
+		return err
+	})
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	page := ListPage{
+		Objects:               make([]ObjectInfo, 0, len(output.Contents)),
+		NextContinuationToken: aws.ToString(output.NextContinuationToken),
+		IsTruncated:           aws.ToBool(output.IsTruncated),
+	}
+	for _, obj := range output.Contents {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Path:    aws.ToString(obj.Key),
+			Size:    aws.ToInt64(obj.Size),
+			ModTime: aws.ToTime(obj.LastModified),
+			ETag:    trimETag(obj.ETag),
+		})
+	}
+	return page, nil
+}
+
+func (s *S3Storage) copySource(key string) *string {
+	return aws.String((&url.URL{Path: s.bucket + "/" + key}).EscapedPath())
+}
+
+// SetStorageClass menyalin objek ke dirinya sendiri dengan storage class baru.
+// Metadata dan header objek ikut tersalin (MetadataDirective COPY).
+func (s *S3Storage) SetStorageClass(ctx context.Context, path, class string) error {
+	if s.client == nil {
+		return fmt.Errorf("s3 client is not initialized")
+	}
+	key := filepath.ToSlash(path)
+
+	return s.retry.Do(ctx, "CopyObject", key, func(ctx context.Context) error {
+		_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:            aws.String(s.bucket),
+			Key:               aws.String(key),
+			CopySource:        s.copySource(key),
+			StorageClass:      s3types.StorageClass(class),
+			MetadataDirective: s3types.MetadataDirectiveCopy,
+		})
+		return mapS3Error(key, err)
+	})
+}
+
+// Move menyalin objek di sisi server lalu menghapus key asal.
+func (s *S3Storage) Move(ctx context.Context, from, to string) error {
+	if err := s.Copy(ctx, from, to); err != nil {
+		return err
+	}
+	return s.Delete(ctx, from)
+}
+
+func (s *S3Storage) Copy(ctx context.Context, from, to string) error {
+	if s.client == nil {
+		return fmt.Errorf("s3 client is not initialized")
+	}
+	fromKey, toKey := filepath.ToSlash(from), filepath.ToSlash(to)
+
+	return s.retry.Do(ctx, "CopyObject", toKey, func(ctx context.Context) error {
+		_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:            aws.String(s.bucket),
+			Key:               aws.String(toKey),
+			CopySource:        s.copySource(fromKey),
+			MetadataDirective: s3types.MetadataDirectiveCopy,
+		})
+		return mapS3Error(fromKey, err)
+	})
+}
@@ -0,0 +1,72 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const maxDeleteObjectsKeys = 1000
+
+func (s *S3Storage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	results := make([]DeleteResult, len(paths))
+	for i, path := range paths {
+		results[i] = DeleteResult{Path: path}
+	}
+
+	if s.client == nil {
+		for i := range results {
+			results[i].Err = fmt.Errorf("s3 client is not initialized")
+		}
+		return results
+	}
+
+	for start := 0; start < len(paths); start += maxDeleteObjectsKeys {
+		end := min(start+maxDeleteObjectsKeys, len(paths))
+		s.deleteChunk(ctx, results[start:end])
+	}
+	return results
+}
+
+func (s *S3Storage) deleteChunk(ctx context.Context, results []DeleteResult) {
+	indexByKey := make(map[string][]int, len(results))
+	objects := make([]s3types.ObjectIdentifier, 0, len(results))
+	for i, result := range results {
+		key := filepath.ToSlash(result.Path)
+		if _, seen := indexByKey[key]; !seen {
+			objects = append(objects, s3types.ObjectIdentifier{Key: aws.String(key)})
+		}
+		indexByKey[key] = append(indexByKey[key], i)
+	}
+
+	var output *s3.DeleteObjectsOutput
+	err := s.retry.Do(ctx, "DeleteObjects", fmt.Sprintf("%d keys", len(objects)), func(ctx context.Context) error {
+		var err error
+		output, err = s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		return err
+	})
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return
+	}
+
+	for _, keyErr := range output.Errors {
+		key := aws.ToString(keyErr.Key)
+		err := fmt.Errorf("%s: %s", aws.ToString(keyErr.Code), aws.ToString(keyErr.Message))
+		for _, i := range indexByKey[key] {
+			results[i].Err = err
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestS3DeleteManyChunksAndMapsErrors(t *testing.T) {
+	fake, client := newFakeS3(t)
+	storage := NewS3Storage(client, S3Options{Bucket: fakeBucket, Retry: RetryPolicy{MaxAttempts: 1}})
+
+	paths := make([]string, 0, maxDeleteObjectsKeys+2)
+	for i := 0; i < maxDeleteObjectsKeys+1; i++ {
+		path := fmt.Sprintf("post_picture/%04d.webp", i)
+		paths = append(paths, path)
+		fake.objects[path] = []byte("x")
+	}
+	// Path ganda di chunk terakhir hanya dikirim sekali tetapi tetap
+	// mendapat hasil sendiri.
+	paths = append(paths, paths[len(paths)-1])
+	fake.deleteErrors["post_picture/0007.webp"] = "AccessDenied"
+
+	results := storage.DeleteMany(context.Background(), paths)
+
+	if len(results) != len(paths) {
+		t.Fatalf("jumlah hasil = %d, want %d", len(results), len(paths))
+	}
+	if len(fake.deleteBatches) != 2 || len(fake.deleteBatches[0]) != maxDeleteObjectsKeys || len(fake.deleteBatches[1]) != 1 {
+		t.Errorf("batch DeleteObjects = %d batch (%d, ...), want 2 batch (%d, 1)", len(fake.deleteBatches), len(fake.deleteBatches[0]), maxDeleteObjectsKeys)
+	}
+	for i, result := range results {
+		if result.Path != paths[i] {
+			t.Fatalf("hasil %d untuk %s, want %s", i, result.Path, paths[i])
+		}
+		wantErr := result.Path == "post_picture/0007.webp"
+		if (result.Err != nil) != wantErr {
+			t.Errorf("%s: err = %v, want error %v", result.Path, result.Err, wantErr)
+		}
+	}
+	if err := results[7].Err; err != nil && !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("error per key = %v, want kode AccessDenied", err)
+	}
+	if len(fake.objects) != 1 {
+		t.Errorf("sisa objek = %d, want 1 (yang ditolak)", len(fake.objects))
+	}
+}
+
+func TestS3DeleteManyRequestFailureMarksChunk(t *testing.T) {
+	fake, client := newFakeS3(t)
+	storage := NewS3Storage(client, S3Options{Bucket: fakeBucket, Retry: RetryPolicy{MaxAttempts: 1}})
+	fake.failures = []int{http.StatusForbidden}
+
+	results := storage.DeleteMany(context.Background(), []string{"a.webp", "b.webp"})
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("%s: tidak ada error saat request DeleteObjects gagal", result.Path)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const maxMultipartParts = 10000
+
+// putMultipart meng-upload reader secara bertahap dengan satu buffer part
+// berukuran partSize, sehingga memori per upload tidak bergantung pada
+// ukuran objek.
+func (s *S3Storage) putMultipart(ctx context.Context, key string, reader io.Reader, opts PutOptions) (err error) {
+	var created *s3.CreateMultipartUploadOutput
+	err = s.retry.Do(ctx, "CreateMultipartUpload", key, func(ctx context.Context) error {
+		var err error
+		created, err = s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:             aws.String(s.bucket),
+			Key:                aws.String(key),
+			ContentType:        optionalString(opts.ContentType),
+			CacheControl:       optionalString(opts.CacheControl),
+			ContentDisposition: optionalString(opts.ContentDisposition),
+			Metadata:           encodeS3Metadata(opts.Metadata),
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("gagal memulai multipart upload: %w", err)
+	}
+	uploadID := created.UploadId
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		_, abortErr := s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(key),
+			UploadId: uploadID,
+		})
+		if abortErr != nil {
+			slog.Warn("Gagal membatalkan multipart upload", "key", key, "error", abortErr)
+		}
+	}()
+
+	buf := make([]byte, s.partSize)
+	var parts []s3types.CompletedPart
+	var partSums [][]byte
+	var total int64
+
+	for partNumber := int32(1); ; partNumber++ {
+		if partNumber > maxMultipartParts {
+			return fmt.Errorf("objek melebihi batas %d part multipart", maxMultipartParts)
+		}
+
+		n, readErr := io.ReadFull(reader, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return readErr
+		}
+		if n == 0 && partNumber > 1 {
+			break
+		}
+
+		var contentMD5 *string
+		if s.verify {
+			sum := md5.Sum(buf[:n])
+			partSums = append(partSums, sum[:])
+			contentMD5 = aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+		}
+
+		var uploaded *s3.UploadPartOutput
+		err := s.retry.Do(ctx, "UploadPart", key, func(ctx context.Context) error {
+			var err error
+			uploaded, err = s.client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        aws.String(s.bucket),
+				Key:           aws.String(key),
+				UploadId:      uploadID,
+				PartNumber:    aws.Int32(partNumber),
+				Body:          bytes.NewReader(buf[:n]),
+				ContentLength: aws.Int64(int64(n)),
+				ContentMD5:    contentMD5,
+			})
+			return mapChecksumError(key, err)
+		})
+		if err != nil {
+			return fmt.Errorf("gagal upload part %d: %w", partNumber, err)
+		}
+		parts = append(parts, s3types.CompletedPart{
+			ETag:       uploaded.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+		total += int64(n)
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	var completed *s3.CompleteMultipartUploadOutput
+	err = s.retry.Do(ctx, "CompleteMultipartUpload", key, func(ctx context.Context) error {
+		var err error
+		completed, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             aws.String(key),
+			UploadId:        uploadID,
+			MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("gagal menyelesaikan multipart upload: %w", err)
+	}
+
+	slog.Debug("Multipart upload selesai", "key", key, "parts", len(parts), "size_bytes", total)
+	if !s.verify {
+		return nil
+	}
+	return s.verifyUploaded(ctx, key, trimETag(completed.ETag), multipartETag(partSums))
+}
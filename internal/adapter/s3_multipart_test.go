@@ -0,0 +1,69 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestS3Storage membuat S3Storage dengan ambang dan ukuran part kecil agar
+// multipart bisa diuji tanpa objek berukuran megabyte.
+func newTestS3Storage(t *testing.T, verify bool) (*S3Storage, *fakeS3) {
+	t.Helper()
+
+	fake, client := newFakeS3(t)
+	storage := NewS3Storage(client, S3Options{
+		Bucket:          fakeBucket,
+		Retry:           RetryPolicy{MaxAttempts: 1},
+		VerifyChecksums: verify,
+	})
+	storage.multipartThreshold = 8
+	storage.partSize = 5
+	return storage, fake
+}
+
+func TestS3PutChoosesSingleOrMultipart(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantParts []int
+	}{
+		{name: "di bawah ambang", data: "1234567"},
+		{name: "tepat di ambang", data: "12345678", wantParts: []int{5, 3}},
+		{name: "beberapa part", data: "123456789012", wantParts: []int{5, 5, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, fake := newTestS3Storage(t, true)
+
+			if err := storage.Put(context.Background(), "post_picture/a.webp", strings.NewReader(tt.data), PutOptions{ContentType: "image/webp"}); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if got := string(fake.objects["post_picture/a.webp"]); got != tt.data {
+				t.Errorf("objek = %q, want %q", got, tt.data)
+			}
+			if !reflect.DeepEqual(fake.partSizes, tt.wantParts) {
+				t.Errorf("ukuran part = %v, want %v", fake.partSizes, tt.wantParts)
+			}
+		})
+	}
+}
+
+func TestS3PutMultipartAbortsOnReadError(t *testing.T) {
+	storage, fake := newTestS3Storage(t, false)
+
+	reader := io.MultiReader(bytes.NewReader([]byte("1234567890")), failingReader{})
+	if err := storage.Put(context.Background(), "a.webp", reader, PutOptions{}); err == nil {
+		t.Fatalf("Put dengan reader gagal tidak mengembalikan error")
+	}
+	if fake.aborted != 1 {
+		t.Errorf("multipart dibatalkan %d kali, want 1", fake.aborted)
+	}
+	if _, ok := fake.objects["a.webp"]; ok {
+		t.Errorf("objek tetap dibuat setelah upload gagal")
+	}
+}
@@ -3,107 +3,211 @@ package adapter
 import (
 	"chrononews-scheduler/internal/config"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log/slog"
-	"os"
+	"io/fs"
 	"path/filepath"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-type StorageAdapter struct {
-	mode   string
-	client *s3.Client
-	bucket string
+type Storage interface {
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error
+	Delete(ctx context.Context, path string) error
+	DeleteMany(ctx context.Context, paths []string) []DeleteResult
+	Stat(ctx context.Context, path string) (ObjectInfo, error)
+	Exists(ctx context.Context, path string) (bool, error)
+	List(ctx context.Context, opts ListOptions) (ListPage, error)
+}
+
+const defaultListMaxKeys = 1000
+
+// DeleteResult dikembalikan DeleteMany dengan urutan yang sama seperti input,
+// satu entri per path. Err nil berarti objek sudah tidak ada di storage.
+type DeleteResult struct {
+	Path string
+	Err  error
+}
+
+type PutOptions struct {
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	Metadata           map[string]string
+}
+
+type ObjectInfo struct {
+	Path               string
+	Size               int64
+	ModTime            time.Time
+	ContentType        string
+	ETag               string
+	CacheControl       string
+	ContentDisposition string
+	Metadata           map[string]string
+}
+
+// ListOptions mengikuti semantik ListObjectsV2: Prefix dicocokkan sebagai
+// awalan string, ContinuationToken diambil dari ListPage sebelumnya.
+type ListOptions struct {
+	Prefix            string
+	ContinuationToken string
+	MaxKeys           int
+}
+
+type ListPage struct {
+	Objects               []ObjectInfo
+	NextContinuationToken string
+	IsTruncated           bool
+}
+
+func (o ListOptions) limit() int {
+	if o.MaxKeys <= 0 || o.MaxKeys > defaultListMaxKeys {
+		return defaultListMaxKeys
+	}
+	return o.MaxKeys
+}
+
+func normalizePrefix(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(prefix))
+	if cleaned == "." {
+		return ""
+	}
+	if strings.HasSuffix(filepath.ToSlash(prefix), "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+	return cleaned
 }
 
-func NewStorageAdapter(cfg *config.Config, s3Client *s3.Client) *StorageAdapter {
-	return &StorageAdapter{
-		mode:   cfg.StorageMode,
-		client: s3Client,
-		bucket: cfg.S3Bucket,
+func existsFromStat(ctx context.Context, s Storage, path string) (bool, error) {
+	_, err := s.Stat(ctx, path)
+	if err == nil {
+		return true, nil
 	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
 }
 
-func (s *StorageAdapter) Open(path string) (io.ReadCloser, error) {
-	if s.mode == "s3" {
-		if s.client == nil {
-			return nil, fmt.Errorf("s3 client is not initialized")
-		}
-		key := filepath.ToSlash(path)
+func paginateSorted(objects []ObjectInfo, opts ListOptions) ListPage {
+	sort.Slice(objects, func(i, j int) bool {
+		return filepath.ToSlash(objects[i].Path) < filepath.ToSlash(objects[j].Path)
+	})
 
-		output, err := s.client.GetObject(context.TODO(), &s3.GetObjectInput{
-			Bucket: aws.String(s.bucket),
-			Key:    aws.String(key),
+	start := 0
+	if opts.ContinuationToken != "" {
+		start = sort.Search(len(objects), func(i int) bool {
+			return filepath.ToSlash(objects[i].Path) > opts.ContinuationToken
 		})
-		if err != nil {
-			return nil, err
-		}
-		return output.Body, nil
 	}
 
-	return os.Open(path)
+	end := start + opts.limit()
+	if end >= len(objects) {
+		return ListPage{Objects: objects[start:]}
+	}
+	return ListPage{
+		Objects:               objects[start:end],
+		NextContinuationToken: filepath.ToSlash(objects[end-1].Path),
+		IsTruncated:           true,
+	}
 }
 
-func (s *StorageAdapter) Put(path string, reader io.Reader, contentType string) error {
-	if s.mode == "s3" {
-		if s.client == nil {
-			return fmt.Errorf("s3 client is not initialized")
-		}
-		key := filepath.ToSlash(path)
+type Factory func(cfg *config.Config) (Storage, error)
 
-		_, err := s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-			Bucket:      aws.String(s.bucket),
-			Key:         aws.String(key),
-			Body:        reader,
-			ContentType: aws.String(contentType),
-		})
-		return err
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Factory{}
+)
+
+func Register(mode string, factory Factory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	mode = strings.ToLower(mode)
+	if factory == nil {
+		panic("adapter: factory storage nil untuk mode " + mode)
+	}
+	if _, exists := backends[mode]; exists {
+		panic("adapter: backend storage sudah terdaftar untuk mode " + mode)
 	}
+	backends[mode] = factory
+}
+
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
 
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+	modes := make([]string, 0, len(backends))
+	for mode := range backends {
+		modes = append(modes, mode)
 	}
+	sort.Strings(modes)
+	return modes
+}
 
-	outFile, err := os.Create(path)
+func NewStorage(cfg *config.Config) (Storage, error) {
+	backend, err := newBackendStorage(cfg, cfg.StorageMode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, copyErr := io.Copy(outFile, reader)
+	// Rate limiter dipasang di luar timeout agar waktu antre tidak memakan
+	// batas waktu operasi.
+	backend = WithTimeouts(backend, Timeouts{
+		Get:    cfg.StorageGetTimeout,
+		Put:    cfg.StoragePutTimeout,
+		Delete: cfg.StorageDeleteTimeout,
+	})
+	return WithRateLimits(backend, RateLimits{
+		ReadRequests:   cfg.StorageReadRPS,
+		ReadBytes:      cfg.StorageReadBytesPerSec,
+		WriteRequests:  cfg.StorageWriteRPS,
+		WriteBytes:     cfg.StorageWriteBytesPerSec,
+		DeleteRequests: cfg.StorageDeleteRPS,
+	}), nil
+}
 
-	closeErr := outFile.Close()
+// newBackendStorage memanggil factory untuk mode tanpa memasang timeout dan
+// rate limiter.
+func newBackendStorage(cfg *config.Config, mode string) (Storage, error) {
+	backendsMu.RLock()
+	factory, ok := backends[strings.ToLower(mode)]
+	backendsMu.RUnlock()
 
-	if copyErr != nil {
-		return copyErr
+	if !ok {
+		return nil, fmt.Errorf("STORAGE_MODE tidak dikenal: '%s' (tersedia: %s)", mode, strings.Join(Backends(), ", "))
 	}
 
-	return closeErr
+	derived := *cfg
+	derived.StorageMode = strings.ToLower(mode)
+	return factory(&derived)
 }
 
-func (s *StorageAdapter) Delete(path string) error {
-	if s.mode == "s3" {
-		if s.client == nil {
-			return fmt.Errorf("s3 client is not initialized")
-		}
-		key := filepath.ToSlash(path)
-		_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
-			Bucket: aws.String(s.bucket),
-			Key:    aws.String(key),
-		})
-		return err
-	}
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
 
-	err := os.Remove(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			slog.Debug("File lokal tidak ditemukan saat penghapusan", "path", path)
-			return nil
-		}
-		return err
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
 	}
-	return nil
+	return r.reader.Read(p)
+}
+
+type contextReadCloser struct {
+	contextReader
+	closer io.Closer
+}
+
+func (r *contextReadCloser) Close() error {
+	return r.closer.Close()
 }
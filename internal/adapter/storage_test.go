@@ -0,0 +1,110 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPaginateSortedWalksAllPages(t *testing.T) {
+	var objects []ObjectInfo
+	for _, name := range []string{"e", "a", "d", "c", "b"} {
+		objects = append(objects, ObjectInfo{Path: "dir/" + name})
+	}
+
+	var got []string
+	opts := ListOptions{MaxKeys: 2}
+	pages := 0
+	for {
+		page := paginateSorted(append([]ObjectInfo(nil), objects...), opts)
+		pages++
+		for _, object := range page.Objects {
+			got = append(got, object.Path)
+		}
+		if !page.IsTruncated {
+			if page.NextContinuationToken != "" {
+				t.Errorf("halaman terakhir masih punya token %q", page.NextContinuationToken)
+			}
+			break
+		}
+		opts.ContinuationToken = page.NextContinuationToken
+	}
+
+	want := []string{"dir/a", "dir/b", "dir/c", "dir/d", "dir/e"}
+	if !reflect.DeepEqual(got, want) || pages != 3 {
+		t.Errorf("hasil = %v dalam %d halaman, want %v dalam 3 halaman", got, pages, want)
+	}
+}
+
+func TestListOptionsLimit(t *testing.T) {
+	for maxKeys, want := range map[int]int{0: defaultListMaxKeys, -1: defaultListMaxKeys, 10: 10, defaultListMaxKeys + 1: defaultListMaxKeys} {
+		if got := (ListOptions{MaxKeys: maxKeys}).limit(); got != want {
+			t.Errorf("limit(%d) = %d, want %d", maxKeys, got, want)
+		}
+	}
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	tests := map[string]string{
+		"":                   "",
+		".":                  "",
+		"post_picture":       "post_picture",
+		"post_picture/":      "post_picture/",
+		"./post_picture//a/": "post_picture/a/",
+		"post_picture/ab":    "post_picture/ab",
+	}
+	for prefix, want := range tests {
+		if got := normalizePrefix(prefix); got != want {
+			t.Errorf("normalizePrefix(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
+func TestLocalStorageListAndExists(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLocalStorage()
+	dir := filepath.ToSlash(t.TempDir())
+
+	for _, name := range []string{"a.webp", "ab.webp", "b.webp", "nested/c.webp"} {
+		if err := storage.Put(ctx, filepath.Join(dir, name), strings.NewReader(name), PutOptions{CacheControl: "max-age=60"}); err != nil {
+			t.Fatalf("Put %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, tempFilePrefix+"x"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: dir + "/", want: []string{"a.webp", "ab.webp", "b.webp", "nested/c.webp"}},
+		{prefix: dir + "/a", want: []string{"a.webp", "ab.webp"}},
+		{prefix: dir + "/nested", want: []string{"nested/c.webp"}},
+		{prefix: dir + "/missing/"},
+	}
+	for _, tt := range tests {
+		page, err := storage.List(ctx, ListOptions{Prefix: tt.prefix})
+		if err != nil {
+			t.Fatalf("List(%s): %v", tt.prefix, err)
+		}
+		var got []string
+		for _, object := range page.Objects {
+			got = append(got, strings.TrimPrefix(filepath.ToSlash(object.Path), dir+"/"))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("List(%s) = %v, want %v", strings.TrimPrefix(tt.prefix, dir), got, tt.want)
+		}
+	}
+
+	if ok, err := storage.Exists(ctx, filepath.Join(dir, "a.webp")); !ok || err != nil {
+		t.Errorf("Exists(a.webp) = %v, %v; want true", ok, err)
+	}
+	if ok, err := storage.Exists(ctx, filepath.Join(dir, "z.webp")); ok || err != nil {
+		t.Errorf("Exists(z.webp) = %v, %v; want false tanpa error", ok, err)
+	}
+}
@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+)
+
+// StorageClassSetter diimplementasikan backend yang bisa memindahkan objek ke
+// storage class lain tanpa mengubah key-nya, misalnya S3 GLACIER_IR.
+type StorageClassSetter interface {
+	SetStorageClass(ctx context.Context, path, class string) error
+}
+
+func setStorageClass(ctx context.Context, s Storage, path, class string) error {
+	setter, ok := s.(StorageClassSetter)
+	if !ok {
+		return fmt.Errorf("backend storage tidak mendukung storage class: %w", errors.ErrUnsupported)
+	}
+	return setter.SetStorageClass(ctx, path, class)
+}
+
+// TieredStorage menulis ke primary dan membaca dari archive bila objek sudah
+// dipindahkan oleh job tiering. Delete menyentuh kedua tier agar objek yang
+// sudah diarsipkan tidak tertinggal; List hanya melihat primary.
+type TieredStorage struct {
+	primary Storage
+	archive Storage
+}
+
+func NewTieredStorage(primary, archive Storage) *TieredStorage {
+	return &TieredStorage{primary: primary, archive: archive}
+}
+
+func (s *TieredStorage) Primary() Storage {
+	return s.primary
+}
+
+func (s *TieredStorage) Archive() Storage {
+	return s.archive
+}
+
+func (s *TieredStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	reader, err := s.primary.Open(ctx, path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return reader, err
+	}
+
+	reader, archiveErr := s.archive.Open(ctx, path)
+	if archiveErr != nil {
+		if errors.Is(archiveErr, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, archiveErr
+	}
+	slog.Debug("Objek dibaca dari tier arsip", "path", path)
+	return reader, nil
+}
+
+func (s *TieredStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	return s.primary.Put(ctx, path, reader, opts)
+}
+
+func (s *TieredStorage) Delete(ctx context.Context, path string) error {
+	return errors.Join(s.primary.Delete(ctx, path), s.archive.Delete(ctx, path))
+}
+
+func (s *TieredStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	results := s.primary.DeleteMany(ctx, paths)
+	for i, archived := range s.archive.DeleteMany(ctx, paths) {
+		results[i].Err = errors.Join(results[i].Err, archived.Err)
+	}
+	return results
+}
+
+func (s *TieredStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	info, err := s.primary.Stat(ctx, path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+
+	info, archiveErr := s.archive.Stat(ctx, path)
+	if archiveErr != nil {
+		if errors.Is(archiveErr, fs.ErrNotExist) {
+			return ObjectInfo{}, err
+		}
+		return ObjectInfo{}, archiveErr
+	}
+	return info, nil
+}
+
+func (s *TieredStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return existsFromStat(ctx, s, path)
+}
+
+func (s *TieredStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	return s.primary.List(ctx, opts)
+}
+
+// SetStorageClass hanya menyentuh primary; objek yang sudah di tier arsip
+// tidak diubah lagi.
+func (s *TieredStorage) SetStorageClass(ctx context.Context, path, class string) error {
+	return setStorageClass(ctx, s.primary, path, class)
+}
+
+// Move memindahkan objek di tier tempat objek itu berada.
+func (s *TieredStorage) Move(ctx context.Context, from, to string) error {
+	err := Move(ctx, s.primary, from, to)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if archiveErr := Move(ctx, s.archive, from, to); !errors.Is(archiveErr, fs.ErrNotExist) {
+		return archiveErr
+	}
+	return err
+}
+
+// Copy menyalin objek di tier tempat objek itu berada.
+func (s *TieredStorage) Copy(ctx context.Context, from, to string) error {
+	err := Copy(ctx, s.primary, from, to)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if archiveErr := Copy(ctx, s.archive, from, to); !errors.Is(archiveErr, fs.ErrNotExist) {
+		return archiveErr
+	}
+	return err
+}
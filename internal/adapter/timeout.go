@@ -0,0 +1,113 @@
+package adapter
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Timeouts membatasi durasi tiap operasi storage. Nilai 0 berarti tanpa batas
+// tambahan selain context milik pemanggil. Stat, Exists dan List memakai
+// batas Get karena ketiganya operasi baca.
+type Timeouts struct {
+	Get    time.Duration
+	Put    time.Duration
+	Delete time.Duration
+}
+
+type timeoutStorage struct {
+	next     Storage
+	timeouts Timeouts
+}
+
+func WithTimeouts(next Storage, timeouts Timeouts) Storage {
+	if timeouts.Get <= 0 && timeouts.Put <= 0 && timeouts.Delete <= 0 {
+		return next
+	}
+	return &timeoutStorage{next: next, timeouts: timeouts}
+}
+
+func withOptionalTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+type cancelOnCloseReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnCloseReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+func (s *timeoutStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Get)
+	reader, err := s.next.Open(ctx, path)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelOnCloseReader{ReadCloser: reader, cancel: cancel}, nil
+}
+
+func (s *timeoutStorage) Put(ctx context.Context, path string, reader io.Reader, opts PutOptions) error {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Put)
+	defer cancel()
+	return s.next.Put(ctx, path, reader, opts)
+}
+
+func (s *timeoutStorage) Delete(ctx context.Context, path string) error {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Delete)
+	defer cancel()
+	return s.next.Delete(ctx, path)
+}
+
+func (s *timeoutStorage) DeleteMany(ctx context.Context, paths []string) []DeleteResult {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Delete)
+	defer cancel()
+	return s.next.DeleteMany(ctx, paths)
+}
+
+func (s *timeoutStorage) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Get)
+	defer cancel()
+	return s.next.Stat(ctx, path)
+}
+
+func (s *timeoutStorage) Exists(ctx context.Context, path string) (bool, error) {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Get)
+	defer cancel()
+	return s.next.Exists(ctx, path)
+}
+
+func (s *timeoutStorage) List(ctx context.Context, opts ListOptions) (ListPage, error) {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Get)
+	defer cancel()
+	return s.next.List(ctx, opts)
+}
+
+func (s *timeoutStorage) SetStorageClass(ctx context.Context, path, class string) error {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Put)
+	defer cancel()
+	return setStorageClass(ctx, s.next, path, class)
+}
+
+func (s *timeoutStorage) Move(ctx context.Context, from, to string) error {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Put)
+	defer cancel()
+	return Move(ctx, s.next, from, to)
+}
+
+func (s *timeoutStorage) Unwrap() Storage {
+	return s.next
+}
+
+func (s *timeoutStorage) Copy(ctx context.Context, from, to string) error {
+	ctx, cancel := withOptionalTimeout(ctx, s.timeouts.Put)
+	defer cancel()
+	return Copy(ctx, s.next, from, to)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -38,9 +39,73 @@ type Config struct {
 	S3SecretKey string
 	S3Endpoint  string
 
+	S3MultipartThresholdMB int
+	S3MultipartPartSizeMB  int
+
+	StorageGetTimeout    time.Duration
+	StoragePutTimeout    time.Duration
+	StorageDeleteTimeout time.Duration
+
+	StorageCacheControl       string
+	StorageContentDisposition string
+	StorageObjectMetadata     bool
+	StorageVerifyUploads      bool
+
+	StorageRetryMaxAttempts    int
+	StorageRetryInitialBackoff time.Duration
+	StorageRetryMaxBackoff     time.Duration
+	StorageRetryMaxElapsed     time.Duration
+
+	StorageReadRPS          int
+	StorageReadBytesPerSec  int
+	StorageWriteRPS         int
+	StorageWriteBytesPerSec int
+	StorageDeleteRPS        int
+
+	MemoryFallbackStorageMode string
+
+	ArchiveStorageMode string
+	ArchiveS3Bucket    string
+	ArchivePrefix      string
+
+	MirrorStorageMode      string
+	MirrorS3Bucket         string
+	MirrorPrefix           string
+	MirrorWritePolicy      string
+	MirrorRepairBatchSize  int
+	MirrorRepairMaxRetries int
+	MirrorReconcileBatch   int
+
+	TieringTarget        string
+	TieringStorageClass  string
+	TieringMinAge        time.Duration
+	TieringIdleThreshold time.Duration
+	TieringBatchSize     int
+
+	TrashEnabled        bool
+	TrashPrefix         string
+	TrashRetention      time.Duration
+	TrashPurgeBatchSize int
+
 	WebPQuality             int
+	OutputFormats           []string
+	VariantWidths           []int
+	AvifQuality             int
+	AvifEffort              int
 	MaxWidth                int
 	MaxHeight               int
+	MaxSourceSizeMB         int
+	MetadataPolicy          string
+	KeepDisplayP3           bool
+	MaxAnimationFrames      int
+	MaxAnimationMegapixels  int
+	QualityMode             string
+	TargetSSIM              float64
+	TargetSizeKB            int
+	AdaptiveMaxAttempts     int
+	AdaptiveMinQuality      int
+	AdaptiveMaxQuality      int
+	Profiles                map[string]CompressionProfile
 	MaxRetries              int
 	CleanupThreshold        time.Duration
 	CleanupBatchSize        int
@@ -66,6 +131,27 @@ func getEnvAsInt(key string, fallback int) (int, error) {
 	}
 	return value, nil
 }
+func getEnvAsList(key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, fallback), ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+func getEnvAsIntList(key, fallback string) ([]int, error) {
+	var values []int
+	for _, item := range getEnvAsList(key, fallback) {
+		value, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("env var %s: invalid integer value '%s'", key, item)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
 func getEnvAsBool(key string, fallback bool) (bool, error) {
 	strValue := getEnv(key, "")
 	if strValue == "" {
@@ -77,6 +163,17 @@ func getEnvAsBool(key string, fallback bool) (bool, error) {
 	}
 	return value, nil
 }
+func getEnvAsFloat(key string, fallback float64) (float64, error) {
+	strValue := getEnv(key, "")
+	if strValue == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseFloat(strValue, 64)
+	if err != nil {
+		return 0, fmt.Errorf("env var %s: invalid float value '%s'", key, strValue)
+	}
+	return value, nil
+}
 func getEnvAsDuration(key string, fallback time.Duration) (time.Duration, error) {
 	strValue := getEnv(key, "")
 	if strValue == "" {
@@ -100,37 +197,24 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("APP_SCHEDULE wajib diisi")
 	}
 
-	cfg.DirAttachment = getEnv("DIR_ATTACHMENT", "post_picture")
-	cfg.DirProfile = getEnv("DIR_PROFILE", "profile_picture")
-	cfg.DirThumbnail = getEnv("DIR_THUMBNAIL", "thumbnail")
-
-	cfg.StorageMode = strings.ToLower(getEnv("STORAGE_MODE", "local"))
-	cfg.S3Bucket = getEnv("S3_BUCKET", "")
-	cfg.S3Region = getEnv("S3_REGION", "ap-southeast-1")
-	cfg.S3AccessKey = getEnv("S3_ACCESS_KEY", "")
-	cfg.S3SecretKey = getEnv("S3_SECRET_KEY", "")
-	cfg.S3Endpoint = getEnv("S3_ENDPOINT", "")
-
-	if cfg.StorageMode == "s3" {
-		if cfg.S3Bucket == "" || cfg.S3Region == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
-			return nil, fmt.Errorf("mode s3 aktif, wajib isi config S3 lengkap")
-		}
+	if err := loadStorageConfig(cfg); err != nil {
+		return nil, err
 	}
 
-	cfg.DBSSLMode = getEnv("DB_SSL_MODE", "disable")
-
-	cfg.DSN = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Jakarta lock_timeout=5000",
-		getEnv("DB_HOST", "localhost"),
-		getEnv("DB_USER", "user"),
-		getEnv("DB_PASSWORD", "password"),
-		getEnv("DB_NAME", "dbname"),
-		getEnv("DB_PORT", "5432"),
-		cfg.DBSSLMode,
-	)
-
-	cfg.LogLevel = getEnv("LOG_LEVEL", "info")
 	cfg.AppMode = getEnv("APP_MODE", "all")
 
+	cfg.TieringTarget = strings.ToLower(getEnv("TIERING_TARGET", ""))
+	cfg.TieringStorageClass = strings.ToUpper(getEnv("TIERING_STORAGE_CLASS", "GLACIER_IR"))
+	if cfg.TieringMinAge, err = getEnvAsDuration("TIERING_MIN_AGE", 365*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TieringIdleThreshold, err = getEnvAsDuration("TIERING_IDLE_THRESHOLD", 90*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TieringBatchSize, err = getEnvAsInt("TIERING_BATCH_SIZE", 100); err != nil {
+		return nil, err
+	}
+
 	if cfg.IsTestMode, err = getEnvAsBool("COMPRESSION_IS_TEST_MODE", false); err != nil {
 		return nil, err
 	}
@@ -149,12 +233,55 @@ func LoadConfig() (*Config, error) {
 	if cfg.WebPQuality, err = getEnvAsInt("COMPRESSION_WEBP_QUALITY", 75); err != nil {
 		return nil, err
 	}
+	cfg.OutputFormats = getEnvAsList("COMPRESSION_OUTPUT_FORMATS", "webp")
+	if cfg.VariantWidths, err = getEnvAsIntList("COMPRESSION_VARIANT_WIDTHS", ""); err != nil {
+		return nil, err
+	}
+	sort.Ints(cfg.VariantWidths)
+	if cfg.AvifQuality, err = getEnvAsInt("COMPRESSION_AVIF_QUALITY", 50); err != nil {
+		return nil, err
+	}
+	if cfg.AvifEffort, err = getEnvAsInt("COMPRESSION_AVIF_EFFORT", 4); err != nil {
+		return nil, err
+	}
 	if cfg.MaxWidth, err = getEnvAsInt("COMPRESSION_MAX_WIDTH", 1980); err != nil {
 		return nil, err
 	}
 	if cfg.MaxHeight, err = getEnvAsInt("COMPRESSION_MAX_HEIGHT", 1980); err != nil {
 		return nil, err
 	}
+	if cfg.MaxSourceSizeMB, err = getEnvAsInt("COMPRESSION_MAX_SOURCE_SIZE_MB", 50); err != nil {
+		return nil, err
+	}
+	cfg.MetadataPolicy = strings.ToLower(getEnv("COMPRESSION_METADATA_POLICY", "keep-icc"))
+	if cfg.KeepDisplayP3, err = getEnvAsBool("COMPRESSION_KEEP_DISPLAY_P3", false); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAnimationFrames, err = getEnvAsInt("COMPRESSION_MAX_ANIMATION_FRAMES", 300); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAnimationMegapixels, err = getEnvAsInt("COMPRESSION_MAX_ANIMATION_MEGAPIXELS", 100); err != nil {
+		return nil, err
+	}
+	cfg.QualityMode = strings.ToLower(getEnv("COMPRESSION_QUALITY_MODE", "fixed"))
+	if cfg.TargetSSIM, err = getEnvAsFloat("COMPRESSION_TARGET_SSIM", 0.97); err != nil {
+		return nil, err
+	}
+	if cfg.TargetSizeKB, err = getEnvAsInt("COMPRESSION_TARGET_SIZE_KB", 200); err != nil {
+		return nil, err
+	}
+	if cfg.AdaptiveMaxAttempts, err = getEnvAsInt("COMPRESSION_ADAPTIVE_MAX_ATTEMPTS", 6); err != nil {
+		return nil, err
+	}
+	if cfg.AdaptiveMinQuality, err = getEnvAsInt("COMPRESSION_ADAPTIVE_MIN_QUALITY", 40); err != nil {
+		return nil, err
+	}
+	if cfg.AdaptiveMaxQuality, err = getEnvAsInt("COMPRESSION_ADAPTIVE_MAX_QUALITY", 95); err != nil {
+		return nil, err
+	}
+	if cfg.Profiles, err = loadProfiles(cfg); err != nil {
+		return nil, err
+	}
 
 	if cfg.CleanupThreshold, err = getEnvAsDuration("CLEANUP_THRESHOLD", 30*24*time.Hour); err != nil {
 		return nil, err
@@ -179,11 +306,153 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// LoadStorageConfig hanya membaca konfigurasi storage, database dan log untuk
+// command pendukung seperti migrate-storage. APP_SCHEDULE dan konfigurasi
+// kompresi tidak diperlukan, dan folder DIR_* tidak dibuat.
+func LoadStorageConfig() (*Config, error) {
+	_ = godotenv.Load()
+
+	cfg := &Config{}
+	if err := loadStorageConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateStorageConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadStorageConfig(cfg *Config) error {
+	var err error
+
+	cfg.DirAttachment = getEnv("DIR_ATTACHMENT", "post_picture")
+	cfg.DirProfile = getEnv("DIR_PROFILE", "profile_picture")
+	cfg.DirThumbnail = getEnv("DIR_THUMBNAIL", "thumbnail")
+
+	cfg.StorageMode = strings.ToLower(getEnv("STORAGE_MODE", "local"))
+	cfg.MemoryFallbackStorageMode = strings.ToLower(getEnv("MEMORY_FALLBACK_STORAGE_MODE", ""))
+	cfg.S3Bucket = getEnv("S3_BUCKET", "")
+	cfg.S3Region = getEnv("S3_REGION", "ap-southeast-1")
+	cfg.S3AccessKey = getEnv("S3_ACCESS_KEY", "")
+	cfg.S3SecretKey = getEnv("S3_SECRET_KEY", "")
+	cfg.S3Endpoint = getEnv("S3_ENDPOINT", "")
+
+	if cfg.StorageMode == "s3" {
+		if cfg.S3Bucket == "" || cfg.S3Region == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+			return fmt.Errorf("mode s3 aktif, wajib isi config S3 lengkap")
+		}
+	}
+
+	if cfg.S3MultipartThresholdMB, err = getEnvAsInt("S3_MULTIPART_THRESHOLD_MB", 8); err != nil {
+		return err
+	}
+	if cfg.S3MultipartPartSizeMB, err = getEnvAsInt("S3_MULTIPART_PART_SIZE_MB", 5); err != nil {
+		return err
+	}
+
+	if cfg.StorageGetTimeout, err = getEnvAsDuration("STORAGE_GET_TIMEOUT", 2*time.Minute); err != nil {
+		return err
+	}
+	if cfg.StoragePutTimeout, err = getEnvAsDuration("STORAGE_PUT_TIMEOUT", 5*time.Minute); err != nil {
+		return err
+	}
+	if cfg.StorageDeleteTimeout, err = getEnvAsDuration("STORAGE_DELETE_TIMEOUT", 30*time.Second); err != nil {
+		return err
+	}
+
+	cfg.StorageCacheControl = getEnv("STORAGE_CACHE_CONTROL", "")
+	cfg.StorageContentDisposition = strings.ToLower(getEnv("STORAGE_CONTENT_DISPOSITION", ""))
+	if cfg.StorageObjectMetadata, err = getEnvAsBool("STORAGE_OBJECT_METADATA", true); err != nil {
+		return err
+	}
+	if cfg.StorageVerifyUploads, err = getEnvAsBool("STORAGE_VERIFY_UPLOADS", true); err != nil {
+		return err
+	}
+
+	if cfg.StorageRetryMaxAttempts, err = getEnvAsInt("STORAGE_RETRY_MAX_ATTEMPTS", 4); err != nil {
+		return err
+	}
+	if cfg.StorageRetryInitialBackoff, err = getEnvAsDuration("STORAGE_RETRY_INITIAL_BACKOFF", 200*time.Millisecond); err != nil {
+		return err
+	}
+	if cfg.StorageRetryMaxBackoff, err = getEnvAsDuration("STORAGE_RETRY_MAX_BACKOFF", 5*time.Second); err != nil {
+		return err
+	}
+	if cfg.StorageRetryMaxElapsed, err = getEnvAsDuration("STORAGE_RETRY_MAX_ELAPSED", 30*time.Second); err != nil {
+		return err
+	}
+
+	if cfg.StorageReadRPS, err = getEnvAsInt("STORAGE_READ_RPS", 0); err != nil {
+		return err
+	}
+	if cfg.StorageReadBytesPerSec, err = getEnvAsInt("STORAGE_READ_BYTES_PER_SEC", 0); err != nil {
+		return err
+	}
+	if cfg.StorageWriteRPS, err = getEnvAsInt("STORAGE_WRITE_RPS", 0); err != nil {
+		return err
+	}
+	if cfg.StorageWriteBytesPerSec, err = getEnvAsInt("STORAGE_WRITE_BYTES_PER_SEC", 0); err != nil {
+		return err
+	}
+	if cfg.StorageDeleteRPS, err = getEnvAsInt("STORAGE_DELETE_RPS", 0); err != nil {
+		return err
+	}
+
+	cfg.ArchiveStorageMode = strings.ToLower(getEnv("ARCHIVE_STORAGE_MODE", ""))
+	cfg.ArchiveS3Bucket = getEnv("ARCHIVE_S3_BUCKET", "")
+	cfg.ArchivePrefix = getEnv("ARCHIVE_PREFIX", "")
+
+	cfg.MirrorStorageMode = strings.ToLower(getEnv("MIRROR_STORAGE_MODE", ""))
+	cfg.MirrorS3Bucket = getEnv("MIRROR_S3_BUCKET", "")
+	cfg.MirrorPrefix = getEnv("MIRROR_PREFIX", "")
+	cfg.MirrorWritePolicy = strings.ToLower(getEnv("MIRROR_WRITE_POLICY", "best-effort"))
+	if cfg.MirrorRepairBatchSize, err = getEnvAsInt("MIRROR_REPAIR_BATCH_SIZE", 100); err != nil {
+		return err
+	}
+	if cfg.MirrorRepairMaxRetries, err = getEnvAsInt("MIRROR_REPAIR_MAX_RETRIES", 5); err != nil {
+		return err
+	}
+	if cfg.MirrorReconcileBatch, err = getEnvAsInt("MIRROR_RECONCILE_BATCH_SIZE", 1000); err != nil {
+		return err
+	}
+
+	if cfg.TrashEnabled, err = getEnvAsBool("TRASH_ENABLED", true); err != nil {
+		return err
+	}
+	cfg.TrashPrefix = getEnv("TRASH_PREFIX", ".trash")
+	if cfg.TrashRetention, err = getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.TrashPurgeBatchSize, err = getEnvAsInt("TRASH_PURGE_BATCH_SIZE", 500); err != nil {
+		return err
+	}
+
+	cfg.DBSSLMode = getEnv("DB_SSL_MODE", "disable")
+
+	cfg.DSN = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Jakarta lock_timeout=5000",
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_USER", "user"),
+		getEnv("DB_PASSWORD", "password"),
+		getEnv("DB_NAME", "dbname"),
+		getEnv("DB_PORT", "5432"),
+		cfg.DBSSLMode,
+	)
+
+	cfg.LogLevel = getEnv("LOG_LEVEL", "info")
+	return nil
+}
+
 func validateConfig(cfg *Config) error {
-	validAppModes := map[string]bool{"all": true, "compression": true, "cleanup": true, "janitor": true, "deletion": true}
+	validAppModes := map[string]bool{"all": true, "compression": true, "cleanup": true, "janitor": true, "deletion": true, "tiering": true, "mirror-repair": true, "trash-purge": true}
 	if !validAppModes[strings.ToLower(cfg.AppMode)] {
 		return fmt.Errorf("APP_MODE tidak valid: '%s'", cfg.AppMode)
 	}
+	if err := validateStorageConfig(cfg); err != nil {
+		return err
+	}
+	if err := validateTiering(cfg); err != nil {
+		return err
+	}
 	if cfg.BatchSize <= 0 {
 		return fmt.Errorf("BATCH_SIZE error")
 	}
@@ -199,6 +468,27 @@ func validateConfig(cfg *Config) error {
 	if cfg.MaxWidth > webpMaxDimension || cfg.MaxHeight > webpMaxDimension {
 		return fmt.Errorf("MAX_WIDTH atau MAX_HEIGHT melebihi batas WebP (%dpx)", webpMaxDimension)
 	}
+	if err := validateOutputFormats(cfg); err != nil {
+		return err
+	}
+	if err := validateProfiles(cfg); err != nil {
+		return err
+	}
+	if cfg.MaxSourceSizeMB < 0 {
+		return fmt.Errorf("COMPRESSION_MAX_SOURCE_SIZE_MB tidak boleh negatif")
+	}
+	if cfg.MetadataPolicy != "strip" && cfg.MetadataPolicy != "keep-icc" && cfg.MetadataPolicy != "keep-copyright" {
+		return fmt.Errorf("COMPRESSION_METADATA_POLICY harus 'strip', 'keep-icc', atau 'keep-copyright'")
+	}
+	if cfg.KeepDisplayP3 && cfg.MetadataPolicy != "keep-icc" {
+		return fmt.Errorf("COMPRESSION_KEEP_DISPLAY_P3 membutuhkan profil ICC, hanya bisa dipakai dengan COMPRESSION_METADATA_POLICY=keep-icc")
+	}
+	if cfg.MaxAnimationFrames <= 0 || cfg.MaxAnimationMegapixels <= 0 {
+		return fmt.Errorf("COMPRESSION_MAX_ANIMATION_FRAMES dan COMPRESSION_MAX_ANIMATION_MEGAPIXELS harus lebih besar dari 0")
+	}
+	if err := validateQualityMode(cfg); err != nil {
+		return err
+	}
 
 	if cfg.StorageMode == "local" {
 		dirsToCheck := []string{cfg.DirAttachment, cfg.DirProfile, cfg.DirThumbnail}
@@ -220,3 +510,205 @@ func validateConfig(cfg *Config) error {
 	}
 	return nil
 }
+
+// validateStorageConfig memeriksa konfigurasi yang dibaca loadStorageConfig.
+func validateStorageConfig(cfg *Config) error {
+	if err := validateMirror(cfg); err != nil {
+		return err
+	}
+	if cfg.MemoryFallbackStorageMode != "" {
+		if cfg.StorageMode != "memory" {
+			return fmt.Errorf("MEMORY_FALLBACK_STORAGE_MODE hanya berlaku untuk STORAGE_MODE memory")
+		}
+		if cfg.MemoryFallbackStorageMode != "local" && cfg.MemoryFallbackStorageMode != "s3" {
+			return fmt.Errorf("MEMORY_FALLBACK_STORAGE_MODE harus 'local' atau 's3'")
+		}
+		if err := validateSecondaryBackend(cfg, "MEMORY_FALLBACK", cfg.MemoryFallbackStorageMode, "", ""); err != nil {
+			return err
+		}
+	}
+	if cfg.ArchiveStorageMode != "" {
+		if err := validateSecondaryBackend(cfg, "ARCHIVE", cfg.ArchiveStorageMode, cfg.ArchiveS3Bucket, cfg.ArchivePrefix); err != nil {
+			return err
+		}
+	}
+	if cfg.TrashEnabled {
+		if strings.Trim(cfg.TrashPrefix, "./") == "" {
+			return fmt.Errorf("TRASH_PREFIX wajib diisi saat TRASH_ENABLED aktif")
+		}
+		if cfg.TrashRetention <= 0 {
+			return fmt.Errorf("TRASH_RETENTION harus lebih besar dari 0")
+		}
+		if cfg.TrashPurgeBatchSize <= 0 {
+			return fmt.Errorf("TRASH_PURGE_BATCH_SIZE harus lebih besar dari 0")
+		}
+	} else if strings.ToLower(cfg.AppMode) == "trash-purge" {
+		return fmt.Errorf("APP_MODE trash-purge membutuhkan TRASH_ENABLED")
+	}
+	if cfg.S3MultipartThresholdMB <= 0 {
+		return fmt.Errorf("S3_MULTIPART_THRESHOLD_MB harus lebih besar dari 0")
+	}
+	if cfg.S3MultipartPartSizeMB < 5 {
+		return fmt.Errorf("S3_MULTIPART_PART_SIZE_MB minimal 5 (batas S3)")
+	}
+	if cfg.StorageGetTimeout < 0 || cfg.StoragePutTimeout < 0 || cfg.StorageDeleteTimeout < 0 {
+		return fmt.Errorf("STORAGE_*_TIMEOUT tidak boleh negatif")
+	}
+	if cfg.StorageContentDisposition != "" && cfg.StorageContentDisposition != "inline" && cfg.StorageContentDisposition != "attachment" {
+		return fmt.Errorf("STORAGE_CONTENT_DISPOSITION harus kosong, 'inline', atau 'attachment'")
+	}
+	if cfg.StorageRetryMaxAttempts < 1 {
+		return fmt.Errorf("STORAGE_RETRY_MAX_ATTEMPTS minimal 1")
+	}
+	if cfg.StorageRetryInitialBackoff < 0 || cfg.StorageRetryMaxBackoff < 0 || cfg.StorageRetryMaxElapsed < 0 {
+		return fmt.Errorf("STORAGE_RETRY_* tidak boleh negatif")
+	}
+	if cfg.StorageReadRPS < 0 || cfg.StorageReadBytesPerSec < 0 || cfg.StorageWriteRPS < 0 ||
+		cfg.StorageWriteBytesPerSec < 0 || cfg.StorageDeleteRPS < 0 {
+		return fmt.Errorf("batas rate STORAGE_*_RPS dan STORAGE_*_BYTES_PER_SEC tidak boleh negatif")
+	}
+	return nil
+}
+
+func validateMirror(cfg *Config) error {
+	if cfg.MirrorStorageMode == "" {
+		if strings.ToLower(cfg.AppMode) == "mirror-repair" {
+			return fmt.Errorf("APP_MODE mirror-repair membutuhkan MIRROR_STORAGE_MODE")
+		}
+		return nil
+	}
+	if err := validateSecondaryBackend(cfg, "MIRROR", cfg.MirrorStorageMode, cfg.MirrorS3Bucket, cfg.MirrorPrefix); err != nil {
+		return err
+	}
+	if cfg.MirrorWritePolicy != "strict" && cfg.MirrorWritePolicy != "best-effort" {
+		return fmt.Errorf("MIRROR_WRITE_POLICY harus 'strict' atau 'best-effort'")
+	}
+	if cfg.MirrorRepairBatchSize <= 0 {
+		return fmt.Errorf("MIRROR_REPAIR_BATCH_SIZE harus lebih besar dari 0")
+	}
+	if cfg.MirrorRepairMaxRetries <= 0 {
+		return fmt.Errorf("MIRROR_REPAIR_MAX_RETRIES harus lebih besar dari 0")
+	}
+	if cfg.MirrorReconcileBatch < 0 {
+		return fmt.Errorf("MIRROR_RECONCILE_BATCH_SIZE tidak boleh negatif")
+	}
+	return nil
+}
+
+func validateTiering(cfg *Config) error {
+	switch cfg.TieringTarget {
+	case "":
+		if strings.ToLower(cfg.AppMode) == "tiering" {
+			return fmt.Errorf("APP_MODE tiering membutuhkan TIERING_TARGET")
+		}
+		return nil
+	case "backend":
+		if cfg.ArchiveStorageMode == "" {
+			return fmt.Errorf("TIERING_TARGET backend membutuhkan ARCHIVE_STORAGE_MODE")
+		}
+	case "storage-class":
+		if cfg.StorageMode != "s3" {
+			return fmt.Errorf("TIERING_TARGET storage-class hanya didukung STORAGE_MODE s3")
+		}
+		if cfg.TieringStorageClass == "" {
+			return fmt.Errorf("TIERING_STORAGE_CLASS wajib diisi")
+		}
+	default:
+		return fmt.Errorf("TIERING_TARGET tidak valid: '%s'", cfg.TieringTarget)
+	}
+
+	if cfg.TieringMinAge <= 0 {
+		return fmt.Errorf("TIERING_MIN_AGE harus lebih besar dari 0")
+	}
+	if cfg.TieringIdleThreshold < 0 {
+		return fmt.Errorf("TIERING_IDLE_THRESHOLD tidak boleh negatif")
+	}
+	if cfg.TieringBatchSize <= 0 {
+		return fmt.Errorf("TIERING_BATCH_SIZE harus lebih besar dari 0")
+	}
+	return nil
+}
+
+// validateOutputFormats memastikan daftar format dan lebar varian valid dan
+// tanpa duplikat. Format pertama menjadi file utama, sisanya disimpan sebagai
+// varian.
+func validateOutputFormats(cfg *Config) error {
+	if err := validateFormatList("COMPRESSION_OUTPUT_FORMATS", cfg.OutputFormats); err != nil {
+		return err
+	}
+	for i, width := range cfg.VariantWidths {
+		if width <= 0 || width > webpMaxDimension {
+			return fmt.Errorf("COMPRESSION_VARIANT_WIDTHS berisi lebar tidak valid: %d", width)
+		}
+		if i > 0 && cfg.VariantWidths[i-1] == width {
+			return fmt.Errorf("COMPRESSION_VARIANT_WIDTHS berisi lebar ganda: %d", width)
+		}
+	}
+	if cfg.AvifQuality < 1 || cfg.AvifQuality > 100 {
+		return fmt.Errorf("COMPRESSION_AVIF_QUALITY harus di antara 1 dan 100")
+	}
+	if cfg.AvifEffort < 1 || cfg.AvifEffort > 9 {
+		return fmt.Errorf("COMPRESSION_AVIF_EFFORT harus di antara 1 dan 9")
+	}
+	return nil
+}
+
+// validateQualityMode memeriksa pengaturan pencarian kualitas adaptif.
+// Pengaturan target hanya diperiksa untuk mode yang memakainya.
+func validateQualityMode(cfg *Config) error {
+	switch cfg.QualityMode {
+	case "fixed":
+		return nil
+	case "ssim":
+		if cfg.TargetSSIM <= 0 || cfg.TargetSSIM >= 1 {
+			return fmt.Errorf("COMPRESSION_TARGET_SSIM harus di antara 0 dan 1")
+		}
+	case "size":
+		if cfg.TargetSizeKB <= 0 {
+			return fmt.Errorf("COMPRESSION_TARGET_SIZE_KB harus lebih besar dari 0")
+		}
+	default:
+		return fmt.Errorf("COMPRESSION_QUALITY_MODE harus 'fixed', 'ssim', atau 'size'")
+	}
+	if cfg.AdaptiveMaxAttempts < 1 {
+		return fmt.Errorf("COMPRESSION_ADAPTIVE_MAX_ATTEMPTS minimal 1")
+	}
+	if cfg.AdaptiveMinQuality < 1 || cfg.AdaptiveMaxQuality > 100 || cfg.AdaptiveMinQuality > cfg.AdaptiveMaxQuality {
+		return fmt.Errorf("COMPRESSION_ADAPTIVE_MIN_QUALITY dan COMPRESSION_ADAPTIVE_MAX_QUALITY harus di antara 1 dan 100, dengan MIN tidak lebih besar dari MAX")
+	}
+	return nil
+}
+
+func validateFormatList(envName string, formats []string) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("%s tidak boleh kosong", envName)
+	}
+	seen := map[string]bool{}
+	for _, format := range formats {
+		if format != "webp" && format != "avif" {
+			return fmt.Errorf("%s tidak valid: '%s' (tersedia: webp, avif)", envName, format)
+		}
+		if seen[format] {
+			return fmt.Errorf("%s berisi format ganda: '%s'", envName, format)
+		}
+		seen[format] = true
+	}
+	return nil
+}
+
+// validateSecondaryBackend memastikan backend tambahan (arsip atau mirror)
+// lengkap dan tidak menunjuk ke lokasi yang sama dengan storage utama.
+func validateSecondaryBackend(cfg *Config, envPrefix, mode, bucket, prefix string) error {
+	if bucket == "" {
+		bucket = cfg.S3Bucket
+	}
+	if mode == "s3" && (bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "") {
+		return fmt.Errorf("%s_STORAGE_MODE s3 membutuhkan bucket dan kredensial S3", envPrefix)
+	}
+
+	sameBackend := mode == cfg.StorageMode && mode != "memory" && (mode != "s3" || bucket == cfg.S3Bucket)
+	if sameBackend && strings.Trim(prefix, "./") == "" {
+		return fmt.Errorf("%s_STORAGE_MODE menunjuk ke lokasi yang sama dengan storage utama, isi %s_PREFIX atau %s_S3_BUCKET", envPrefix, envPrefix, envPrefix)
+	}
+	return nil
+}
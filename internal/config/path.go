@@ -0,0 +1,23 @@
+package config
+
+import (
+	"chrononews-scheduler/internal/constant"
+	"path/filepath"
+)
+
+// ResolvePath memetakan tipe file ke folder/prefix DIR_* yang sesuai. Tipe
+// yang tidak dikenal diperlakukan sebagai attachment.
+func (c *Config) ResolvePath(fileType, fileName string) string {
+	var folder string
+	switch fileType {
+	case constant.FileTypeAttachment:
+		folder = c.DirAttachment
+	case constant.FileTypeProfile:
+		folder = c.DirProfile
+	case constant.FileTypeThumbnail:
+		folder = c.DirThumbnail
+	default:
+		folder = c.DirAttachment
+	}
+	return filepath.Join(folder, fileName)
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"chrononews-scheduler/internal/constant"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Mode crop profil. CropCover memotong bagian tengah, sedangkan
+// CropAttention dan CropEntropy memakai smart crop libvips untuk memilih area
+// yang paling menarik.
+const (
+	CropFit       = "fit"
+	CropCover     = "cover"
+	CropAttention = "attention"
+	CropEntropy   = "entropy"
+)
+
+// CompressionProfile adalah pengaturan kompresi untuk satu tipe file.
+// Quality dipakai encoder WebP; AVIF tetap memakai COMPRESSION_AVIF_QUALITY.
+type CompressionProfile struct {
+	Quality      int
+	MaxWidth     int
+	MaxHeight    int
+	Lossless     bool
+	Crop         string
+	AspectRatio  string
+	AspectWidth  int
+	AspectHeight int
+	Formats      []string
+}
+
+// CropBox mengembalikan ukuran kotak output untuk mode cover: kotak terbesar
+// dengan rasio AspectRatio yang muat di MaxWidth x MaxHeight, atau kotak
+// MaxWidth x MaxHeight itu sendiri bila rasio tidak diatur.
+func (p CompressionProfile) CropBox() (int, int) {
+	if p.AspectWidth <= 0 || p.AspectHeight <= 0 {
+		return p.MaxWidth, p.MaxHeight
+	}
+	width := p.MaxWidth
+	height := max(1, width*p.AspectHeight/p.AspectWidth)
+	if height > p.MaxHeight {
+		height = p.MaxHeight
+		width = max(1, height*p.AspectWidth/p.AspectHeight)
+	}
+	return width, height
+}
+
+// IsCover melaporkan apakah profil mengisi kotak lalu memotong gambar.
+func (p CompressionProfile) IsCover() bool {
+	return p.Crop != CropFit
+}
+
+// profileOverride adalah isi satu profil di COMPRESSION_PROFILES_FILE. Field
+// yang kosong mewarisi nilai global COMPRESSION_*.
+type profileOverride struct {
+	Quality     *int     `json:"quality"`
+	MaxWidth    *int     `json:"max_width"`
+	MaxHeight   *int     `json:"max_height"`
+	Lossless    *bool    `json:"lossless"`
+	Crop        *string  `json:"crop"`
+	AspectRatio *string  `json:"aspect_ratio"`
+	Formats     []string `json:"formats"`
+}
+
+var profileTypes = []string{constant.FileTypeAttachment, constant.FileTypeProfile, constant.FileTypeThumbnail}
+
+// defaultAspectRatios adalah rasio bawaan tipe file yang dipotong dengan mode
+// cover: foto profil persegi dan thumbnail 16:9.
+var defaultAspectRatios = map[string]string{
+	constant.FileTypeProfile:   "1:1",
+	constant.FileTypeThumbnail: "16:9",
+}
+
+// ProfileFor memilih profil berdasarkan tipe file. Tipe yang tidak dikenal
+// memakai profil attachment, sama seperti ResolvePath.
+func (c *Config) ProfileFor(fileType string) CompressionProfile {
+	if profile, ok := c.Profiles[fileType]; ok {
+		return profile
+	}
+	return c.Profiles[constant.FileTypeAttachment]
+}
+
+// loadProfiles menyusun profil per tipe file dengan urutan prioritas: nilai
+// global COMPRESSION_*, lalu COMPRESSION_PROFILES_FILE, lalu env
+// COMPRESSION_<TIPE>_*.
+func loadProfiles(cfg *Config) (map[string]CompressionProfile, error) {
+	overrides := map[string]profileOverride{}
+	if path := getEnv("COMPRESSION_PROFILES_FILE", ""); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca COMPRESSION_PROFILES_FILE: %w", err)
+		}
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			return nil, fmt.Errorf("COMPRESSION_PROFILES_FILE tidak valid: %w", err)
+		}
+		for name := range overrides {
+			if !isProfileType(name) {
+				return nil, fmt.Errorf("COMPRESSION_PROFILES_FILE: profil tidak dikenal '%s' (tersedia: %s)", name, strings.Join(profileTypes, ", "))
+			}
+		}
+	}
+
+	profiles := make(map[string]CompressionProfile, len(profileTypes))
+	for _, fileType := range profileTypes {
+		profile := CompressionProfile{
+			Quality:   cfg.WebPQuality,
+			MaxWidth:  cfg.MaxWidth,
+			MaxHeight: cfg.MaxHeight,
+			Crop:      CropFit,
+			Formats:   cfg.OutputFormats,
+		}
+		if ratio, ok := defaultAspectRatios[fileType]; ok {
+			profile.Crop = CropCover
+			profile.AspectRatio = ratio
+		}
+		overrides[fileType].apply(&profile)
+
+		envPrefix := "COMPRESSION_" + strings.ToUpper(fileType) + "_"
+		var err error
+		if profile.Quality, err = getEnvAsInt(envPrefix+"QUALITY", profile.Quality); err != nil {
+			return nil, err
+		}
+		if profile.MaxWidth, err = getEnvAsInt(envPrefix+"MAX_WIDTH", profile.MaxWidth); err != nil {
+			return nil, err
+		}
+		if profile.MaxHeight, err = getEnvAsInt(envPrefix+"MAX_HEIGHT", profile.MaxHeight); err != nil {
+			return nil, err
+		}
+		if profile.Lossless, err = getEnvAsBool(envPrefix+"LOSSLESS", profile.Lossless); err != nil {
+			return nil, err
+		}
+		profile.Crop = strings.ToLower(getEnv(envPrefix+"CROP", profile.Crop))
+		profile.AspectRatio = strings.TrimSpace(getEnv(envPrefix+"ASPECT_RATIO", profile.AspectRatio))
+		if profile.AspectWidth, profile.AspectHeight, err = parseAspectRatio(profile.AspectRatio); err != nil {
+			return nil, fmt.Errorf("%sASPECT_RATIO tidak valid: %w", envPrefix, err)
+		}
+		profile.Formats = getEnvAsList(envPrefix+"FORMATS", strings.Join(profile.Formats, ","))
+
+		profiles[fileType] = profile
+	}
+	return profiles, nil
+}
+
+func (o profileOverride) apply(profile *CompressionProfile) {
+	if o.Quality != nil {
+		profile.Quality = *o.Quality
+	}
+	if o.MaxWidth != nil {
+		profile.MaxWidth = *o.MaxWidth
+	}
+	if o.MaxHeight != nil {
+		profile.MaxHeight = *o.MaxHeight
+	}
+	if o.Lossless != nil {
+		profile.Lossless = *o.Lossless
+	}
+	if o.Crop != nil {
+		profile.Crop = strings.ToLower(*o.Crop)
+	}
+	if o.AspectRatio != nil {
+		profile.AspectRatio = strings.TrimSpace(*o.AspectRatio)
+	}
+	if len(o.Formats) > 0 {
+		formats := make([]string, len(o.Formats))
+		for i, format := range o.Formats {
+			formats[i] = strings.ToLower(strings.TrimSpace(format))
+		}
+		profile.Formats = formats
+	}
+}
+
+// parseAspectRatio membaca rasio "W:H" seperti "16:9". String kosong berarti
+// rasio mengikuti kotak MaxWidth x MaxHeight.
+func parseAspectRatio(ratio string) (int, int, error) {
+	if ratio == "" {
+		return 0, 0, nil
+	}
+	width, height, found := strings.Cut(ratio, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("'%s' harus berformat W:H", ratio)
+	}
+	w, errW := strconv.Atoi(strings.TrimSpace(width))
+	h, errH := strconv.Atoi(strings.TrimSpace(height))
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("'%s' harus berisi dua bilangan bulat positif", ratio)
+	}
+	return w, h, nil
+}
+
+func isProfileType(name string) bool {
+	for _, fileType := range profileTypes {
+		if name == fileType {
+			return true
+		}
+	}
+	return false
+}
+
+func validateProfiles(cfg *Config) error {
+	for _, fileType := range profileTypes {
+		profile := cfg.Profiles[fileType]
+		envPrefix := "COMPRESSION_" + strings.ToUpper(fileType) + "_"
+		if profile.Quality < 1 || profile.Quality > 100 {
+			return fmt.Errorf("%sQUALITY harus di antara 1 dan 100", envPrefix)
+		}
+		if profile.MaxWidth <= 0 || profile.MaxHeight <= 0 {
+			return fmt.Errorf("%sMAX_WIDTH dan %sMAX_HEIGHT harus lebih besar dari 0", envPrefix, envPrefix)
+		}
+		if profile.MaxWidth > webpMaxDimension || profile.MaxHeight > webpMaxDimension {
+			return fmt.Errorf("%sMAX_WIDTH atau %sMAX_HEIGHT melebihi batas WebP (%dpx)", envPrefix, envPrefix, webpMaxDimension)
+		}
+		switch profile.Crop {
+		case CropFit, CropCover, CropAttention, CropEntropy:
+		default:
+			return fmt.Errorf("%sCROP harus '%s', '%s', '%s', atau '%s'", envPrefix, CropFit, CropCover, CropAttention, CropEntropy)
+		}
+		if err := validateFormatList(envPrefix+"FORMATS", profile.Formats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"chrononews-scheduler/internal/model"
+	"fmt"
 	"log"
 	"log/slog"
 
@@ -18,3 +20,47 @@ func ConnectDB(dsn string) {
 	}
 	slog.Info("Koneksi database berhasil.")
 }
+
+// EnsureSchema menambahkan kolom dan tabel milik scheduler yang belum ada.
+// Skema inti tabel file tetap dikelola ChronoNewsAPI.
+func EnsureSchema() error {
+	migrator := DB.Migrator()
+
+	for _, field := range []string{"SourceHash", "ContentHash", "StorageTier", "LastAccessedAt"} {
+		if !migrator.HasColumn(&model.File{}, field) {
+			if err := migrator.AddColumn(&model.File{}, field); err != nil {
+				return fmt.Errorf("gagal menambah kolom file.%s: %w", field, err)
+			}
+		}
+		if !migrator.HasIndex(&model.File{}, field) {
+			if err := migrator.CreateIndex(&model.File{}, field); err != nil {
+				return fmt.Errorf("gagal membuat index file.%s: %w", field, err)
+			}
+		}
+	}
+
+	// Kualitas encode file utama, diisi kompresi (termasuk mode adaptif).
+	if !migrator.HasColumn(&model.File{}, "Quality") {
+		if err := migrator.AddColumn(&model.File{}, "Quality"); err != nil {
+			return fmt.Errorf("gagal menambah kolom file.Quality: %w", err)
+		}
+	}
+
+	// Penanda antrean hapus yang tidak boleh menyentuh varian file.
+	if !migrator.HasColumn(&model.SourceFileToDelete{}, "KeepVariants") {
+		if err := migrator.AddColumn(&model.SourceFileToDelete{}, "KeepVariants"); err != nil {
+			return fmt.Errorf("gagal menambah kolom source_files_to_delete.KeepVariants: %w", err)
+		}
+	}
+
+	// Status "rejected" dipakai precheck kompresi untuk source yang tidak
+	// valid. ADD VALUE tidak bisa dijalankan di dalam transaksi.
+	if err := DB.Exec("ALTER TYPE file_status ADD VALUE IF NOT EXISTS 'rejected'").Error; err != nil {
+		return fmt.Errorf("gagal menambah status file 'rejected': %w", err)
+	}
+
+	if err := DB.AutoMigrate(&model.FileVariant{}, &model.MirrorRepair{}, &model.StorageMigration{}, &model.StorageMigrationFailure{}, &model.TrashEntry{}); err != nil {
+		return fmt.Errorf("gagal menyiapkan tabel milik scheduler: %w", err)
+	}
+	return nil
+}
@@ -11,12 +11,38 @@ type File struct {
 	LastError      *string `gorm:"column:last_error;type:varchar(255)"`
 	UsedByPostID   *int32  `gorm:"column:used_by_post_id;index"`
 	UsedByUserID   *int32  `gorm:"column:used_by_user_id;index"`
+	SourceHash     *string `gorm:"column:source_hash;type:varchar(64);index"`
+	ContentHash    *string `gorm:"column:content_hash;type:varchar(64);index"`
+	StorageTier    *string `gorm:"column:storage_tier;type:varchar(32);index"`
+	LastAccessedAt *int64  `gorm:"column:last_accessed_at;index"`
+	Quality        *int    `gorm:"column:quality;type:smallint"`
 }
 
 func (File) TableName() string {
 	return "file"
 }
 
+// FileVariant adalah encoding tambahan dari sebuah file: format lain di
+// samping file utama (photo.avif) atau varian lebar untuk srcset
+// (photo@640w.webp). Tabel ini dikelola scheduler.
+type FileVariant struct {
+	ID          int32  `gorm:"column:id;primaryKey;type:integer;autoIncrement;not null"`
+	FileID      int32  `gorm:"column:file_id;uniqueIndex:idx_file_variant_name"`
+	Name        string `gorm:"column:name;type:varchar(255);uniqueIndex:idx_file_variant_name"`
+	Format      string `gorm:"column:format;type:varchar(16)"`
+	ContentType string `gorm:"column:content_type;type:varchar(64)"`
+	Width       int    `gorm:"column:width"`
+	Height      int    `gorm:"column:height"`
+	Size        int64  `gorm:"column:size"`
+	ContentHash string `gorm:"column:content_hash;type:varchar(64)"`
+	CreatedAt   int64  `gorm:"column:created_at;autoCreateTime:unixtime"`
+	UpdatedAt   int64  `gorm:"column:updated_at;autoCreateTime:unixtime;autoUpdateTime:unixtime"`
+}
+
+func (FileVariant) TableName() string {
+	return "file_variant"
+}
+
 type DeadLetterQueue struct {
 	ID           int32  `gorm:"column:id;primaryKey;type:integer;autoIncrement;not null"`
 	CreatedAt    int64  `gorm:"column:created_at;autoCreateTime:unixtime"`
@@ -30,11 +56,16 @@ func (DeadLetterQueue) TableName() string {
 	return "dead_letter_queue"
 }
 
+// SourceFileToDelete adalah antrean hapus objek. KeepVariants diisi scheduler
+// untuk objek yang bukan file utama (source asli setelah kompresi, hasil
+// dedup) sehingga varian file tidak ikut dihapus; entri dari ChronoNewsAPI
+// memakai default false dan menghapus varian bersama file utamanya.
 type SourceFileToDelete struct {
 	ID             int32   `gorm:"column:id;primaryKey"`
 	FileID         int32   `gorm:"column:file_id"`
 	File           File    `gorm:"foreignKey:FileID"`
 	SourcePath     string  `gorm:"column:source_path;type:varchar(512)"`
+	KeepVariants   bool    `gorm:"column:keep_variants;not null;default:false"`
 	FailedAttempts int     `gorm:"column:failed_attempts;default:0"`
 	LastError      *string `gorm:"column:last_error;type:varchar(255)"`
 	CreatedAt      int64   `gorm:"column:created_at;autoCreateTime:unixtime"`
@@ -43,3 +74,68 @@ type SourceFileToDelete struct {
 func (SourceFileToDelete) TableName() string {
 	return "source_files_to_delete"
 }
+
+type MirrorRepair struct {
+	ID             int32   `gorm:"column:id;primaryKey;type:integer;autoIncrement;not null"`
+	Path           string  `gorm:"column:path;type:varchar(512);uniqueIndex"`
+	FailedAttempts int     `gorm:"column:failed_attempts;default:0"`
+	LastError      *string `gorm:"column:last_error;type:varchar(255)"`
+	CreatedAt      int64   `gorm:"column:created_at;autoCreateTime:unixtime"`
+	UpdatedAt      int64   `gorm:"column:updated_at;autoCreateTime:unixtime;autoUpdateTime:unixtime"`
+}
+
+func (MirrorRepair) TableName() string {
+	return "mirror_repair_queue"
+}
+
+type StorageMigration struct {
+	ID          int32  `gorm:"column:id;primaryKey;type:integer;autoIncrement;not null"`
+	Name        string `gorm:"column:name;type:varchar(255);uniqueIndex"`
+	LastFileID  int32  `gorm:"column:last_file_id;default:0"`
+	Archived    int    `gorm:"column:archived;default:0"`
+	Copied      int    `gorm:"column:copied;default:0"`
+	Skipped     int    `gorm:"column:skipped;default:0"`
+	Missing     int    `gorm:"column:missing;default:0"`
+	Failed      int    `gorm:"column:failed;default:0"`
+	CopiedBytes int64  `gorm:"column:copied_bytes;default:0"`
+	CompletedAt *int64 `gorm:"column:completed_at"`
+	CreatedAt   int64  `gorm:"column:created_at;autoCreateTime:unixtime"`
+	UpdatedAt   int64  `gorm:"column:updated_at;autoCreateTime:unixtime;autoUpdateTime:unixtime"`
+}
+
+func (StorageMigration) TableName() string {
+	return "storage_migration"
+}
+
+type StorageMigrationFailure struct {
+	ID          int32  `gorm:"column:id;primaryKey;type:integer;autoIncrement;not null"`
+	MigrationID int32  `gorm:"column:migration_id;uniqueIndex:idx_storage_migration_failure"`
+	FileID      int32  `gorm:"column:file_id;uniqueIndex:idx_storage_migration_failure"`
+	Path        string `gorm:"column:path;type:varchar(512)"`
+	LastError   string `gorm:"column:last_error;type:varchar(255)"`
+	CreatedAt   int64  `gorm:"column:created_at;autoCreateTime:unixtime"`
+	UpdatedAt   int64  `gorm:"column:updated_at;autoCreateTime:unixtime;autoUpdateTime:unixtime"`
+}
+
+func (StorageMigrationFailure) TableName() string {
+	return "storage_migration_failure"
+}
+
+type TrashEntry struct {
+	ID           int32   `gorm:"column:id;primaryKey;type:integer;autoIncrement;not null"`
+	FileID       *int32  `gorm:"column:file_id;index"`
+	Reason       string  `gorm:"column:reason;type:varchar(32)"`
+	OriginalPath string  `gorm:"column:original_path;type:varchar(512)"`
+	TrashPath    *string `gorm:"column:trash_path;type:varchar(512);index"`
+	FileSnapshot *string `gorm:"column:file_snapshot;type:text"`
+	// VariantSnapshot diisi untuk objek varian agar record file_variant bisa
+	// dibuat ulang saat file induknya dipulihkan.
+	VariantSnapshot *string `gorm:"column:variant_snapshot;type:text"`
+	DeletedAt       int64   `gorm:"column:deleted_at;index"`
+	RestoredAt      *int64  `gorm:"column:restored_at"`
+	PurgedAt        *int64  `gorm:"column:purged_at"`
+}
+
+func (TrashEntry) TableName() string {
+	return "trash_entry"
+}
@@ -3,9 +3,9 @@ package service
 import (
 	"chrononews-scheduler/internal/adapter"
 	"chrononews-scheduler/internal/config"
-	"chrononews-scheduler/internal/constant"
 	"chrononews-scheduler/internal/database"
 	"chrononews-scheduler/internal/model"
+	"context"
 	"fmt"
 	"log/slog"
 	"path/filepath"
@@ -14,14 +14,14 @@ import (
 	"gorm.io/gorm"
 )
 
-func CleanupOrphanedFiles(cfg *config.Config, batchSize int, storage *adapter.StorageAdapter) {
+func CleanupOrphanedFiles(ctx context.Context, cfg *config.Config, batchSize int, storage adapter.Storage) {
 	slog.Info("Memulai tugas pembersihan orphaned file...")
 
 	thresholdTime := time.Now().Add(-cfg.CleanupThreshold)
 	unixThreshold := thresholdTime.Unix()
 
 	var orphanedFiles []model.File
-	err := database.DB.Where("used_by_post_id IS NULL AND used_by_user_id IS NULL AND created_at < ?", unixThreshold).
+	err := database.DB.WithContext(ctx).Where("used_by_post_id IS NULL AND used_by_user_id IS NULL AND created_at < ?", unixThreshold).
 		Limit(batchSize).
 		Find(&orphanedFiles).Error
 
@@ -35,33 +35,65 @@ func CleanupOrphanedFiles(cfg *config.Config, batchSize int, storage *adapter.St
 	}
 	slog.Info(fmt.Sprintf("Ditemukan %d orphaned file.", len(orphanedFiles)))
 
-	var idsToDeleteFromDB []int32
-	for _, file := range orphanedFiles {
-		var folder string
-		switch file.Type {
-		case constant.FileTypeAttachment:
-			folder = cfg.DirAttachment
-		case constant.FileTypeProfile:
-			folder = cfg.DirProfile
-		case constant.FileTypeThumbnail:
-			folder = cfg.DirThumbnail
-		default:
-			folder = cfg.DirAttachment
-		}
+	filePaths := make([]string, len(orphanedFiles))
+	for i, file := range orphanedFiles {
+		filePaths[i] = cfg.ResolvePath(file.Type, file.Name)
+	}
 
-		filePath := filepath.Join(folder, file.Name)
+	referenced, err := findSharedObjects(ctx, orphanedFiles)
+	if err != nil {
+		slog.Error("Gagal memeriksa referensi objek bersama", "error", err)
+		return
+	}
 
-		err := storage.Delete(filePath)
+	deletedAt := time.Now()
+	variantDirs := make(map[int32]string, len(orphanedFiles))
+	for i, file := range orphanedFiles {
+		variantDirs[file.ID] = filepath.Dir(filePaths[i])
+	}
+	trashEntries, failedVariants, err := removeFileVariants(ctx, cfg, storage, trashReasonCleanup, variantDirs, deletedAt)
+	if err != nil {
+		slog.Error("Gagal mengambil data varian file", "error", err)
+		return
+	}
+
+	var idsToDeleteFromDB []int32
+	var pathsToDelete []string
+	var fileIndexes []int
+	for i, file := range orphanedFiles {
+		if failedVariants[file.ID] {
+			continue
+		}
+		if referenced[objectRefKey(file.Type, file.Name)] {
+			slog.Debug("Objek masih dipakai record lain, hanya record yang dihapus", "path", filePaths[i], "file_id", file.ID)
+			idsToDeleteFromDB = append(idsToDeleteFromDB, file.ID)
+			trashEntries = appendTrashEntry(trashEntries, file, removedObject{Path: filePaths[i]}, deletedAt)
+			continue
+		}
+		pathsToDelete = append(pathsToDelete, filePaths[i])
+		fileIndexes = append(fileIndexes, i)
+	}
 
-		if err == nil {
+	for i, result := range removeObjects(ctx, cfg, storage, pathsToDelete) {
+		file := orphanedFiles[fileIndexes[i]]
+		if result.Err == nil {
 			idsToDeleteFromDB = append(idsToDeleteFromDB, file.ID)
+			trashEntries = appendTrashEntry(trashEntries, file, result, deletedAt)
 		} else {
-			slog.Error("Gagal hapus file storage", "path", filePath, "error", err)
+			slog.Error("Gagal hapus file storage", "path", result.Path, "error", result.Err)
 		}
 	}
 
 	if len(idsToDeleteFromDB) > 0 {
 		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if cfg.TrashEnabled && len(trashEntries) > 0 {
+				if err := tx.Create(&trashEntries).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("file_id IN ?", idsToDeleteFromDB).Delete(&model.FileVariant{}).Error; err != nil {
+				return err
+			}
 			return tx.Where("id IN ?", idsToDeleteFromDB).Delete(&model.File{}).Error
 		})
 		if err != nil {
@@ -70,11 +102,51 @@ func CleanupOrphanedFiles(cfg *config.Config, batchSize int, storage *adapter.St
 	}
 }
 
-func ProcessDeletionQueue(batchSize int, maxRetries int, storage *adapter.StorageAdapter) {
+func appendTrashEntry(entries []model.TrashEntry, file model.File, removed removedObject, at time.Time) []model.TrashEntry {
+	fileID := file.ID
+	entry, err := newTrashEntry(trashReasonCleanup, &fileID, removed, &file, at)
+	if err != nil {
+		slog.Warn("Gagal membuat snapshot file untuk trash", "file_id", file.ID, "error", err)
+		return entries
+	}
+	return append(entries, entry)
+}
+
+func objectRefKey(fileType, name string) string {
+	return fileType + "/" + name
+}
+
+// findSharedObjects mengembalikan objek (type/name) dari batch yang masih
+// direferensikan record file di luar batch, misalnya hasil dedup.
+func findSharedObjects(ctx context.Context, files []model.File) (map[string]bool, error) {
+	names := make([]string, 0, len(files))
+	ids := make([]int32, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+		ids = append(ids, file.ID)
+	}
+
+	var others []model.File
+	err := database.DB.WithContext(ctx).
+		Select("id", "type", "name").
+		Where("name IN ? AND id NOT IN ?", names, ids).
+		Find(&others).Error
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool, len(others))
+	for _, other := range others {
+		referenced[objectRefKey(other.Type, other.Name)] = true
+	}
+	return referenced, nil
+}
+
+func ProcessDeletionQueue(ctx context.Context, cfg *config.Config, batchSize int, maxRetries int, storage adapter.Storage) {
 	slog.Info("Memulai pemroses antrean penghapusan file sumber...", "batch_size", batchSize)
 
 	var queueItems []model.SourceFileToDelete
-	err := database.DB.Where("failed_attempts < ?", maxRetries).
+	err := database.DB.WithContext(ctx).Where("failed_attempts < ?", maxRetries).
 		Limit(batchSize).
 		Find(&queueItems).Error
 	if err != nil {
@@ -86,23 +158,84 @@ func ProcessDeletionQueue(batchSize int, maxRetries int, storage *adapter.Storag
 		return
 	}
 
-	var successCount, failedCount int
-	for _, item := range queueItems {
-		err := storage.Delete(item.SourcePath)
+	variantDirs := variantDirsForQueue(queueItems)
 
-		if err == nil {
-			database.DB.Delete(&item)
-			successCount++
-		} else {
-			slog.Error("Antrean Hapus: Gagal menghapus file.", "path", item.SourcePath, "error", err)
-			errorMessage := err.Error()
-			database.DB.Model(&item).Updates(map[string]interface{}{
-				"failed_attempts": gorm.Expr("failed_attempts + 1"),
-				"last_error":      &errorMessage,
-			})
+	deletedAt := time.Now()
+	trashEntries, failedVariants, err := removeFileVariants(ctx, cfg, storage, trashReasonDeletion, variantDirs, deletedAt)
+	if err != nil {
+		slog.Error("Antrean Hapus: Gagal mengambil data varian file.", "error", err)
+		return
+	}
+
+	var failedCount int
+	var pendingItems []model.SourceFileToDelete
+	for _, item := range queueItems {
+		if failedVariants[item.FileID] {
+			markDeletionFailed(item, fmt.Errorf("gagal menghapus varian file %d", item.FileID))
 			failedCount++
+			continue
+		}
+		pendingItems = append(pendingItems, item)
+	}
+
+	sourcePaths := make([]string, len(pendingItems))
+	for i, item := range pendingItems {
+		sourcePaths[i] = item.SourcePath
+	}
+
+	var successIDs []int32
+	var variantFileIDs []int32
+	for i, result := range removeObjects(ctx, cfg, storage, sourcePaths) {
+		item := pendingItems[i]
+		if result.Err == nil {
+			successIDs = append(successIDs, item.ID)
+			if _, ok := variantDirs[item.FileID]; ok {
+				variantFileIDs = append(variantFileIDs, item.FileID)
+			}
+			if result.TrashPath != "" {
+				fileID := item.FileID
+				entry, err := newTrashEntry(trashReasonDeletion, &fileID, result, nil, deletedAt)
+				if err != nil {
+					slog.Warn("Gagal membuat entri trash", "file_id", item.FileID, "path", item.SourcePath, "error", err)
+					continue
+				}
+				trashEntries = append(trashEntries, entry)
+			}
+			continue
+		}
+
+		slog.Error("Antrean Hapus: Gagal menghapus file.", "path", item.SourcePath, "error", result.Err)
+		markDeletionFailed(item, result.Err)
+		failedCount++
+	}
+
+	successCount := len(successIDs)
+	if successCount > 0 {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if len(trashEntries) > 0 {
+				if err := tx.Create(&trashEntries).Error; err != nil {
+					return err
+				}
+			}
+			if len(variantFileIDs) > 0 {
+				if err := tx.Where("file_id IN ?", variantFileIDs).Delete(&model.FileVariant{}).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Where("id IN ?", successIDs).Delete(&model.SourceFileToDelete{}).Error
+		})
+		if err != nil {
+			slog.Error("Antrean Hapus: Gagal menghapus entri antrean.", "error", err)
 		}
 	}
 
 	slog.Info("Pemroses antrean penghapusan selesai.", "berhasil", successCount, "gagal", failedCount)
 }
+
+func markDeletionFailed(item model.SourceFileToDelete, err error) {
+	errorMessage := truncateError(err.Error())
+	database.DB.Model(&item).Updates(map[string]interface{}{
+		"failed_attempts": gorm.Expr("failed_attempts + 1"),
+		"last_error":      &errorMessage,
+	})
+}
@@ -0,0 +1,175 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/vips"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// headerPeekSize adalah jumlah byte awal source yang dibaca untuk memeriksa
+// header sebelum libvips memuat gambar.
+const headerPeekSize = 128 << 10
+
+const exifOrientationTag = 0x0112
+
+// sourceHeader adalah hasil pemeriksaan header source. Orientation 0 berarti
+// tag orientasi tidak ditemukan di header; MaybeRotated menandai source yang
+// orientasinya tidak bisa dibaca dari header (misalnya EXIF WebP yang
+// biasanya ada di akhir file).
+type sourceHeader struct {
+	Format       string
+	Orientation  int
+	MaybeRotated bool
+	Animated     bool
+}
+
+// inspectHeader membaca orientasi EXIF dan penanda animasi dari byte awal
+// source tanpa mengonsumsi reader.
+func inspectHeader(reader *bufio.Reader, format string) sourceHeader {
+	header := sourceHeader{Format: format}
+	head, err := reader.Peek(headerPeekSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return header
+	}
+
+	switch format {
+	case "jpeg":
+		header.Orientation = jpegOrientation(head)
+	case "tiff":
+		header.Orientation = tiffOrientation(head)
+	case "png":
+		header.Orientation, header.MaybeRotated = pngOrientation(head)
+	case "webp":
+		header.Animated, header.MaybeRotated = webpFlags(head)
+	case "gif":
+		// Jumlah frame GIF baru diketahui setelah seluruh file dibaca.
+		header.Animated = true
+	case "heif":
+		// Rotasi HEIF disimpan di box irot/imir yang tidak diperiksa di sini.
+		header.MaybeRotated = true
+	}
+	return header
+}
+
+// loadAccess memilih mode akses libvips. Akses sekuensial menjaga pemakaian
+// RAM tetap rendah karena gambar didekode sambil dibaca; akses acak hanya
+// dipakai bila gambar harus dibaca lebih dari sekali: beberapa output
+// (format atau lebar), rotasi orientasi, animasi, smart crop, atau pencarian
+// kualitas adaptif.
+func loadAccess(cfg *config.Config, profile config.CompressionProfile, outputs []*encodedOutput, header sourceHeader) vips.Access {
+	switch {
+	case len(outputs) > 1,
+		header.Orientation > 1,
+		header.MaybeRotated,
+		header.Animated,
+		profile.Crop == config.CropAttention || profile.Crop == config.CropEntropy,
+		len(outputs) == 1 && useAdaptiveQuality(cfg, profile, outputs[0].Format, false):
+		return vips.AccessRandom
+	}
+	return vips.AccessSequentialUnbuffered
+}
+
+// jpegOrientation mencari segmen APP1 Exif sebelum data gambar (SOS).
+func jpegOrientation(data []byte) int {
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			return 0
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 {
+			return 0
+		}
+		segment := data[pos+4 : min(pos+2+length, len(data))]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 0
+}
+
+// tiffOrientation membaca tag orientasi dari IFD0 struktur TIFF, dipakai
+// oleh file TIFF dan blok EXIF.
+func tiffOrientation(data []byte) int {
+	if len(data) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(data[4:]))
+	if offset < 8 || offset+2 > len(data) {
+		return 0
+	}
+	count := int(order.Uint16(data[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(data) {
+			return 0
+		}
+		if order.Uint16(data[entry:]) != exifOrientationTag {
+			continue
+		}
+		orientation := int(order.Uint16(data[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 0
+		}
+		return orientation
+	}
+	return 0
+}
+
+// pngOrientation mencari chunk eXIf sebelum IDAT. Bila header lebih panjang
+// dari byte yang dibaca, orientasinya dianggap tidak diketahui.
+func pngOrientation(data []byte) (int, bool) {
+	pos := 8
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunk := string(data[pos+4 : pos+8])
+		switch chunk {
+		case "IDAT", "IEND":
+			return 0, false
+		case "eXIf":
+			end := pos + 8 + length
+			if length < 0 || end > len(data) {
+				return 0, true
+			}
+			return tiffOrientation(data[pos+8 : end]), false
+		}
+		pos += 12 + length
+	}
+	return 0, true
+}
+
+// webpFlags membaca flag animasi dan EXIF dari chunk VP8X. WebP sederhana
+// (VP8/VP8L) tidak punya keduanya.
+func webpFlags(data []byte) (animated, hasExif bool) {
+	const (
+		flagAnimation = 0x02
+		flagExif      = 0x08
+	)
+	if len(data) < 21 || string(data[12:16]) != "VP8X" {
+		return false, false
+	}
+	flags := data[20]
+	return flags&flagAnimation != 0, flags&flagExif != 0
+}
@@ -0,0 +1,86 @@
+package compression
+
+import (
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/vips"
+	"fmt"
+)
+
+// loadPages menentukan jumlah frame yang dimuat. Hanya GIF dan WebP yang
+// dimuat seluruhnya (n=-1) karena bisa beranimasi; TIFF dan HEIF multi-halaman
+// tetap memakai halaman pertama.
+func loadPages(sourceFormat string) int {
+	if sourceFormat == "gif" || sourceFormat == "webp" {
+		return -1
+	}
+	return 0
+}
+
+func isAnimated(img *vips.Image) bool {
+	return img.Pages() > 1
+}
+
+// checkAnimationLimits menolak animasi yang melebihi batas frame atau total
+// piksel. Pengecekan hanya membaca header, sebelum frame di-decode.
+func checkAnimationLimits(img *vips.Image, cfg *config.Config) error {
+	if !isAnimated(img) {
+		return nil
+	}
+	frames := img.Pages()
+	if frames > cfg.MaxAnimationFrames {
+		return fmt.Errorf("%w: animasi %d frame melebihi batas %d", errSourceRejected, frames, cfg.MaxAnimationFrames)
+	}
+	pixels := int64(img.Width()) * int64(img.Height())
+	if limit := int64(cfg.MaxAnimationMegapixels) * 1_000_000; pixels > limit {
+		return fmt.Errorf("%w: animasi %d piksel melebihi batas %d MP", errSourceRejected, pixels, cfg.MaxAnimationMegapixels)
+	}
+	return nil
+}
+
+// resizeFrames mengubah skala gambar. Untuk animasi, frame disusun vertikal
+// dalam satu gambar, sehingga skala vertikal disesuaikan agar tinggi setiap
+// frame tetap bilangan bulat dan page-height diperbarui.
+func resizeFrames(img *vips.Image, scale float64) error {
+	pages := img.Pages()
+	if pages <= 1 {
+		return img.Resize(scale, nil)
+	}
+
+	pageHeight := max(1, int(float64(img.PageHeight())*scale+0.5))
+	opts := vips.DefaultResizeOptions()
+	opts.Vscale = float64(pageHeight*pages) / float64(img.Height())
+	if err := img.Resize(scale, opts); err != nil {
+		return err
+	}
+
+	pageHeight = img.Height() / pages
+	if img.Height() != pageHeight*pages {
+		if err := img.ExtractArea(0, 0, img.Width(), pageHeight*pages); err != nil {
+			return err
+		}
+	}
+	return img.SetPageHeight(pageHeight)
+}
+
+// firstFrame menyalin frame pertama animasi untuk encoder yang tidak
+// mendukung animasi.
+func firstFrame(img *vips.Image) (*vips.Image, error) {
+	frame, err := img.Copy(nil)
+	if err != nil {
+		return nil, err
+	}
+	pageHeight := img.PageHeight()
+	if err := frame.ExtractArea(0, 0, img.Width(), pageHeight); err != nil {
+		frame.Close()
+		return nil, err
+	}
+	if err := frame.SetPages(1); err != nil {
+		frame.Close()
+		return nil, err
+	}
+	if err := frame.SetPageHeight(pageHeight); err != nil {
+		frame.Close()
+		return nil, err
+	}
+	return frame, nil
+}
@@ -0,0 +1,143 @@
+package compression
+
+import (
+	"chrononews-scheduler/vips"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// convertedInterpretations adalah ruang warna tanpa profil ICC yang diubah
+// ke sRGB dengan vips_colourspace.
+var convertedInterpretations = []struct {
+	interpretation vips.Interpretation
+	name           string
+}{
+	{vips.InterpretationLab, "Lab"},
+	{vips.InterpretationLabs, "LabS"},
+	{vips.InterpretationXyz, "XYZ"},
+	{vips.InterpretationRgb16, "RGB16"},
+	{vips.InterpretationScrgb, "scRGB"},
+	{vips.InterpretationHsv, "HSV"},
+}
+
+// convertToSRGB mengubah gambar ke sRGB sebelum encode agar warna tidak
+// bergeser di browser yang mengabaikan profil ICC. Profil Display P3 bisa
+// dipertahankan dengan keepP3. Nilai kembaliannya adalah deskripsi konversi
+// untuk log, kosong bila gambar sudah sRGB.
+func convertToSRGB(img *vips.Image, keepP3 bool) (string, error) {
+	interpretation := img.Interpretation()
+	profile, hasProfile := img.GetICCProfile()
+	description := iccDescription(profile)
+
+	switch {
+	case interpretation == vips.InterpretationCmyk:
+		source := "CMYK"
+		if description != "" {
+			source = fmt.Sprintf("CMYK (%s)", description)
+		}
+		// Profil CMYK bawaan libvips dipakai bila source tidak membawa profil.
+		if err := img.IccTransform("srgb", &vips.IccTransformOptions{
+			Intent:       vips.IntentRelative,
+			Embedded:     true,
+			InputProfile: "cmyk",
+			Depth:        8,
+		}); err != nil {
+			return "", fmt.Errorf("vips icc_transform %s: %w", source, err)
+		}
+		return source + " -> sRGB", nil
+
+	case hasProfile && len(profile) > 0:
+		if isSRGBProfile(description) {
+			return "", nil
+		}
+		if keepP3 && isDisplayP3Profile(description) {
+			return fmt.Sprintf("%s (dipertahankan)", description), nil
+		}
+		if description == "" {
+			description = "profil ICC tanpa nama"
+		}
+		if err := img.IccTransform("srgb", &vips.IccTransformOptions{
+			Intent:   vips.IntentRelative,
+			Embedded: true,
+			Depth:    8,
+		}); err != nil {
+			return "", fmt.Errorf("vips icc_transform %s: %w", description, err)
+		}
+		return description + " -> sRGB", nil
+
+	default:
+		for _, converted := range convertedInterpretations {
+			if converted.interpretation != interpretation {
+				continue
+			}
+			if err := img.Colourspace(vips.InterpretationSrgb, nil); err != nil {
+				return "", fmt.Errorf("vips colourspace %s: %w", converted.name, err)
+			}
+			return converted.name + " -> sRGB", nil
+		}
+		return "", nil
+	}
+}
+
+func isSRGBProfile(description string) bool {
+	return strings.Contains(strings.ToLower(description), "srgb")
+}
+
+func isDisplayP3Profile(description string) bool {
+	return strings.Contains(strings.ToLower(description), "p3")
+}
+
+// iccDescription membaca tag 'desc' dari profil ICC, baik tipe v2
+// (textDescriptionType) maupun v4 (multiLocalizedUnicodeType). Profil yang
+// tidak bisa dibaca menghasilkan string kosong.
+func iccDescription(profile []byte) string {
+	const headerSize = 128
+	if len(profile) < headerSize+4 {
+		return ""
+	}
+	count := int(binary.BigEndian.Uint32(profile[headerSize:]))
+	for i := 0; i < count; i++ {
+		entry := headerSize + 4 + i*12
+		if entry+12 > len(profile) {
+			return ""
+		}
+		if string(profile[entry:entry+4]) != "desc" {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(profile[entry+4:]))
+		size := int(binary.BigEndian.Uint32(profile[entry+8:]))
+		if offset < 0 || size < 12 || offset+size > len(profile) {
+			return ""
+		}
+		return parseDescTag(profile[offset : offset+size])
+	}
+	return ""
+}
+
+func parseDescTag(tag []byte) string {
+	switch string(tag[:4]) {
+	case "desc":
+		length := int(binary.BigEndian.Uint32(tag[8:]))
+		if length <= 0 || 12+length > len(tag) {
+			return ""
+		}
+		return strings.TrimSpace(strings.TrimRight(string(tag[12:12+length]), "\x00"))
+	case "mluc":
+		if len(tag) < 28 || binary.BigEndian.Uint32(tag[8:]) == 0 {
+			return ""
+		}
+		length := int(binary.BigEndian.Uint32(tag[20:]))
+		offset := int(binary.BigEndian.Uint32(tag[24:]))
+		if length <= 0 || offset+length > len(tag) {
+			return ""
+		}
+		units := make([]uint16, length/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(tag[offset+i*2:])
+		}
+		return strings.TrimSpace(strings.TrimRight(string(utf16.Decode(units)), "\x00"))
+	}
+	return ""
+}
@@ -1,48 +1,60 @@
 package compression
 
 import (
+	"bufio"
 	"bytes"
 	"chrononews-scheduler/internal/adapter"
 	"chrononews-scheduler/internal/config"
-	"chrononews-scheduler/internal/constant"
 	"chrononews-scheduler/internal/database"
 	"chrononews-scheduler/internal/model"
 	"chrononews-scheduler/vips"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
+	"mime"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func resolvePath(cfg *config.Config, fileType, fileName string) string {
-	var folder string
-	switch fileType {
-	case constant.FileTypeAttachment:
-		folder = cfg.DirAttachment
-	case constant.FileTypeProfile:
-		folder = cfg.DirProfile
-	case constant.FileTypeThumbnail:
-		folder = cfg.DirThumbnail
-	default:
-		folder = cfg.DirAttachment
-	}
-	return filepath.Join(folder, fileName)
+type compressionOutput struct {
+	SourceHash  string
+	ContentHash string
+	Size        int64
+	Quality     int
+	Variants    []variantOutput
+	// Reused diisi bila source identik sudah pernah dikompresi; upload
+	// dilewati dan record tersebut dipakai ulang.
+	Reused *model.File
+}
+
+// variantOutput adalah hasil encode tambahan selain file utama.
+type variantOutput struct {
+	Name        string
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+	Size        int64
+	ContentHash string
 }
 
-func ExecuteCompressionTask(ctx context.Context, cfg *config.Config, task model.File, storage *adapter.StorageAdapter) error {
-	sourcePath := resolvePath(cfg, task.Type, task.Name)
-	originalName := strings.TrimSuffix(task.Name, filepath.Ext(task.Name))
-	newFileName := fmt.Sprintf("%s.webp", originalName)
-	outputPath := resolvePath(cfg, task.Type, newFileName)
+func ExecuteCompressionTask(ctx context.Context, cfg *config.Config, task model.File, storage adapter.Storage) (compressionOutput, error) {
+	sourcePath := cfg.ResolvePath(task.Type, task.Name)
+
+	if err := precheckSource(ctx, cfg, storage, sourcePath); err != nil {
+		return compressionOutput{}, err
+	}
 
-	reader, err := storage.Open(sourcePath)
+	reader, err := storage.Open(ctx, sourcePath)
 	if err != nil {
-		return fmt.Errorf("gagal membuka source (%s): %w", sourcePath, err)
+		return compressionOutput{}, fmt.Errorf("gagal membuka source (%s): %w", sourcePath, err)
 	}
 
 	defer func() {
@@ -51,84 +63,266 @@ func ExecuteCompressionTask(ctx context.Context, cfg *config.Config, task model.
 		}
 	}()
 
-	processedReader, err := processImageWithReader(reader, cfg)
+	bufferedSource := bufio.NewReaderSize(reader, headerPeekSize)
+	sourceFormat, err := sniffImage(bufferedSource)
 	if err != nil {
-		return fmt.Errorf("gagal menyiapkan proses gambar: %w", err)
+		return compressionOutput{}, err
+	}
+	header := inspectHeader(bufferedSource, sourceFormat)
+
+	sourceHasher := newHashingReader(bufferedSource)
+	outputs, info, err := processImageWithReader(sourceHasher, cfg, cfg.ProfileFor(task.Type), header)
+	if err != nil {
+		return compressionOutput{}, fmt.Errorf("gagal menyiapkan proses gambar: %w", err)
 	}
 
 	defer func() {
-		if err := processedReader.Close(); err != nil {
-			slog.Warn("Gagal menutup processed reader", "error", err)
+		for _, encoded := range outputs {
+			if err := encoded.Reader.Close(); err != nil {
+				slog.Warn("Gagal menutup processed reader", "format", encoded.Format.Name, "error", err)
+			}
 		}
 	}()
 
-	var buf bytes.Buffer
-	_, err = io.Copy(&buf, processedReader)
+	var written []string
+	cleanupWritten := func() {
+		cleanupCtx := context.WithoutCancel(ctx)
+		for _, path := range written {
+			if delErr := storage.Delete(cleanupCtx, path); delErr != nil {
+				slog.Warn("Gagal cleanup file (upload fail)", "path", path, "error", delErr)
+			}
+		}
+	}
+
+	// File utama di-encode penuh ke memori sebelum upload. Setelah itu
+	// libvips selesai membaca source, sehingga source_hash sudah lengkap dan
+	// source identik yang pernah dikompresi bisa dipakai ulang tanpa upload.
+	mainOutput, err := io.ReadAll(outputs[0].Reader)
 	if err != nil {
-		return fmt.Errorf("gagal mem-buffer hasil kompresi: %w", err)
+		return compressionOutput{}, fmt.Errorf("gagal memproses gambar: %w", err)
 	}
 
-	if cfg.IsTestMode {
-		slog.Debug("TEST MODE: Simulasi sukses. File tidak disimpan.", "mock_path", outputPath, "size_bytes", buf.Len())
-		return nil
+	var sourceHash string
+	if err := sourceHasher.drain(); err != nil {
+		slog.Warn("Gagal membaca sisa source untuk hash, source_hash dilewati", "path", sourcePath, "error", err)
+	} else {
+		sourceHash = sourceHasher.Sum()
+		reused, err := lookupCompressedSource(ctx, task, sourceHash)
+		if err != nil {
+			return compressionOutput{}, fmt.Errorf("gagal mencari source identik: %w", err)
+		}
+		if reused != nil {
+			slog.Info("Dedup: source identik sudah dikompresi, upload dilewati",
+				"task_id", task.ID,
+				"duplicate_of", reused.ID,
+				"name", reused.Name,
+			)
+			return reusedOutput(sourceHash, reused), nil
+		}
 	}
 
-	errChan := make(chan error, 1)
-	go func() {
-		uErr := storage.Put(outputPath, &buf, "image/webp")
-		errChan <- uErr
-	}()
+	output := compressionOutput{SourceHash: sourceHash}
+	for i, encoded := range outputs {
+		fileName := encoded.fileName(task.Name)
+		outputPath := cfg.ResolvePath(task.Type, fileName)
+
+		var body io.Reader = bytes.NewReader(mainOutput)
+		if i > 0 {
+			bufferedOutput := bufio.NewReader(encoded.Reader)
+			if _, err := bufferedOutput.Peek(1); err != nil {
+				if errors.Is(err, errRenditionSkipped) {
+					slog.Debug("Varian dilewati", "file", task.Name, "format", encoded.Format.Name, "width", encoded.TargetWidth)
+					continue
+				}
+				cleanupWritten()
+				return compressionOutput{}, fmt.Errorf("gagal memproses gambar: %w", err)
+			}
+			body = bufferedOutput
+		}
+
+		renditionInfo := *info
+		renditionInfo.Width, renditionInfo.Height = encoded.Width, encoded.Height
 
-	select {
-	case <-ctx.Done():
-		go func() {
-			if err := storage.Delete(outputPath); err != nil {
-				slog.Warn("Gagal cleanup file (timeout)", "path", outputPath, "error", err)
+		outputHasher := newHashingReader(body)
+
+		written = append(written, outputPath)
+		if err := storage.Put(ctx, outputPath, outputHasher, buildPutOptions(cfg, task, fileName, encoded.Format, &renditionInfo)); err != nil {
+			cleanupWritten()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return compressionOutput{}, ctxErr
 			}
-		}()
-		return ctx.Err()
-	case err := <-errChan:
-		if err != nil {
-			go func() {
-				if err := storage.Delete(outputPath); err != nil {
-					slog.Warn("Gagal cleanup file (upload fail)", "path", outputPath, "error", err)
-				}
-			}()
-			return fmt.Errorf("gagal menyimpan hasil: %w", err)
+			return compressionOutput{}, fmt.Errorf("gagal menyimpan hasil: %w", err)
 		}
+
+		if cfg.StorageVerifyUploads {
+			if err := verifyStoredOutput(ctx, storage, outputPath, outputHasher.Size()); err != nil {
+				cleanupWritten()
+				return compressionOutput{}, fmt.Errorf("verifikasi hasil gagal: %w", err)
+			}
+		}
+
+		if i == 0 {
+			output.ContentHash = outputHasher.Sum()
+			output.Size = outputHasher.Size()
+			output.Quality = info.Quality
+			continue
+		}
+		output.Variants = append(output.Variants, variantOutput{
+			Name:        fileName,
+			Format:      encoded.Format.Name,
+			ContentType: encoded.Format.ContentType,
+			Width:       encoded.Width,
+			Height:      encoded.Height,
+			Size:        outputHasher.Size(),
+			ContentHash: outputHasher.Sum(),
+		})
 	}
 
+	if info.ColourConversion != "" {
+		slog.Info("Profil warna diproses", "task_id", task.ID, "file", task.Name, "conversion", info.ColourConversion)
+	}
+
+	return output, nil
+}
+
+// reusedOutput menyusun hasil untuk task yang memakai ulang record file lain
+// dengan source identik.
+func reusedOutput(sourceHash string, reused *model.File) compressionOutput {
+	output := compressionOutput{SourceHash: sourceHash, Reused: reused}
+	if reused.ContentHash != nil {
+		output.ContentHash = *reused.ContentHash
+	}
+	if reused.Quality != nil {
+		output.Quality = *reused.Quality
+	}
+	return output
+}
+
+// verifyStoredOutput memastikan objek yang tersimpan berukuran sama dengan
+// hasil encode. Checksum isi sudah dicocokkan oleh backend saat Put.
+func verifyStoredOutput(ctx context.Context, storage adapter.Storage, path string, size int64) error {
+	info, err := storage.Stat(ctx, path)
+	if err != nil {
+		return err
+	}
+	if info.Size != size {
+		return fmt.Errorf("%w: %s berukuran %d byte, diharapkan %d", adapter.ErrChecksumMismatch, path, info.Size, size)
+	}
 	return nil
 }
 
-func handleSuccess(task model.File, cfg *config.Config) {
+func buildPutOptions(cfg *config.Config, task model.File, fileName string, format outputFormat, info *imageInfo) adapter.PutOptions {
+	opts := adapter.PutOptions{
+		ContentType:  format.ContentType,
+		CacheControl: cfg.StorageCacheControl,
+	}
+
+	if cfg.StorageContentDisposition != "" {
+		opts.ContentDisposition = mime.FormatMediaType(cfg.StorageContentDisposition, map[string]string{"filename": fileName})
+	}
+
+	if cfg.StorageObjectMetadata {
+		originalFormat := info.Format
+		if originalFormat == "" || originalFormat == string(vips.ImageTypeUnknown) {
+			originalFormat = strings.TrimPrefix(strings.ToLower(filepath.Ext(task.Name)), ".")
+		}
+		opts.Metadata = map[string]string{
+			"file-id":         strconv.Itoa(int(task.ID)),
+			"original-name":   task.Name,
+			"original-format": originalFormat,
+			"original-width":  strconv.Itoa(info.OriginalWidth),
+			"original-height": strconv.Itoa(info.OriginalHeight),
+			"width":           strconv.Itoa(info.Width),
+			"height":          strconv.Itoa(info.Height),
+		}
+	}
+	return opts
+}
+
+func handleSuccess(task model.File, output compressionOutput, cfg *config.Config) {
 	if cfg.IsTestMode {
-		slog.Debug("TEST MODE: Skip update DB.", "task_id", task.ID)
+		slog.Debug("TEST MODE: Skip update DB.", "task_id", task.ID, "content_hash", output.ContentHash)
 		return
 	}
 
-	originalNameWithoutExt := strings.TrimSuffix(task.Name, filepath.Ext(task.Name))
-	newWebPFileName := fmt.Sprintf("%s.webp", originalNameWithoutExt)
-	sourcePath := resolvePath(cfg, task.Type, task.Name)
-
-	deletionEntry := model.SourceFileToDelete{
-		FileID:     task.ID,
-		SourcePath: sourcePath,
+	newFileName := outputFileName(task.Name, primaryFormat(cfg.ProfileFor(task.Type)))
+	if output.Reused != nil {
+		newFileName = output.Reused.Name
 	}
+	sourcePath := cfg.ResolvePath(task.Type, task.Name)
+	outputPath := cfg.ResolvePath(task.Type, newFileName)
 
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(&task).Updates(map[string]interface{}{
-			"status":     "compressed",
-			"last_error": nil,
-			"name":       newWebPFileName,
-		}).Error; err != nil {
-			return err
+		finalName := newFileName
+		variantsFrom := output.Reused
+		// Source WebP ditimpa output dengan nama yang sama, jadi tidak ada
+		// source yang perlu dihapus.
+		var deletionEntries []model.SourceFileToDelete
+		if sourcePath != outputPath {
+			deletionEntries = append(deletionEntries, model.SourceFileToDelete{
+				FileID:       task.ID,
+				SourcePath:   sourcePath,
+				KeepVariants: true,
+			})
+		}
+
+		if output.ContentHash != "" && output.Reused == nil {
+			duplicate, err := findDuplicateOutput(tx, task, output.ContentHash)
+			if err != nil {
+				return err
+			}
+			if duplicate != nil && duplicate.Name != newFileName {
+				refs, err := countFileReferences(tx, task.Type, newFileName, task.ID)
+				if err != nil {
+					return err
+				}
+				finalName = duplicate.Name
+				variantsFrom = duplicate
+				// Objek utama dan varian yang baru ditulis dibuang satu per
+				// satu; record varian task diganti salinan milik duplikat.
+				if refs == 0 {
+					deletionEntries = append(deletionEntries, model.SourceFileToDelete{
+						FileID:       task.ID,
+						SourcePath:   outputPath,
+						KeepVariants: true,
+					})
+					for _, variant := range output.Variants {
+						deletionEntries = append(deletionEntries, model.SourceFileToDelete{
+							FileID:       task.ID,
+							SourcePath:   cfg.ResolvePath(task.Type, variant.Name),
+							KeepVariants: true,
+						})
+					}
+				}
+				slog.Info("Dedup: hasil kompresi identik, memakai objek yang sudah ada",
+					"task_id", task.ID,
+					"duplicate_of", duplicate.ID,
+					"name", finalName,
+				)
+			}
 		}
 
-		if err := tx.Create(&deletionEntry).Error; err != nil {
+		updates := map[string]interface{}{
+			"status":       "compressed",
+			"last_error":   nil,
+			"name":         finalName,
+			"content_hash": nullableString(output.ContentHash),
+			"source_hash":  nullableString(output.SourceHash),
+			"quality":      nullableQuality(output.Quality),
+		}
+		if err := tx.Model(&task).Updates(updates).Error; err != nil {
 			return err
 		}
-		return nil
+
+		if len(deletionEntries) > 0 {
+			if err := tx.Create(&deletionEntries).Error; err != nil {
+				return err
+			}
+		}
+		if variantsFrom != nil {
+			return copyVariants(tx, task, variantsFrom.ID)
+		}
+		return saveVariants(tx, task, output.Variants)
 	})
 
 	if err != nil {
@@ -136,6 +330,67 @@ func handleSuccess(task model.File, cfg *config.Config) {
 	}
 }
 
+// saveVariants mencatat varian hasil encode. Record lama dengan nama yang sama
+// (misalnya dari percobaan sebelumnya) diperbarui.
+func saveVariants(tx *gorm.DB, task model.File, variants []variantOutput) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	records := make([]model.FileVariant, len(variants))
+	for i, variant := range variants {
+		records[i] = model.FileVariant{
+			FileID:      task.ID,
+			Name:        variant.Name,
+			Format:      variant.Format,
+			ContentType: variant.ContentType,
+			Width:       variant.Width,
+			Height:      variant.Height,
+			Size:        variant.Size,
+			ContentHash: variant.ContentHash,
+		}
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "file_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"format", "content_type", "width", "height", "size", "content_hash", "updated_at"}),
+	}).Create(&records).Error
+}
+
+// copyVariants menyalin record varian milik file yang dipakai ulang ke task
+// sehingga keduanya menunjuk objek varian yang sama.
+func copyVariants(tx *gorm.DB, task model.File, fromID int32) error {
+	var existing []model.FileVariant
+	if err := tx.Where("file_id = ?", fromID).Order("id ASC").Find(&existing).Error; err != nil {
+		return err
+	}
+	variants := make([]variantOutput, len(existing))
+	for i, variant := range existing {
+		variants[i] = variantOutput{
+			Name:        variant.Name,
+			Format:      variant.Format,
+			ContentType: variant.ContentType,
+			Width:       variant.Width,
+			Height:      variant.Height,
+			Size:        variant.Size,
+			ContentHash: variant.ContentHash,
+		}
+	}
+	return saveVariants(tx, task, variants)
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func nullableQuality(value int) *int {
+	if value <= 0 {
+		return nil
+	}
+	return &value
+}
+
 func handleFailure(task model.File, err error, cfg *config.Config) {
 	if cfg.IsTestMode {
 		slog.Error("TEST MODE: Simulasi Gagal.", "error", err)
@@ -149,6 +404,16 @@ func handleFailure(task model.File, err error, cfg *config.Config) {
 		errorMessage = errorMessage[:250] + "..."
 	}
 
+	if errors.Is(err, errSourceRejected) {
+		slog.Warn("Source ditolak, tugas tidak akan diproses ulang", "file", task.Name, "reason", errorMessage)
+		if err := database.DB.Model(&task).Updates(map[string]interface{}{
+			"status": "rejected", "last_error": &errorMessage,
+		}).Error; err != nil {
+			slog.Error("Gagal menandai tugas sebagai rejected", "task_id", task.ID, "error", err)
+		}
+		return
+	}
+
 	if newAttempts >= cfg.MaxRetries {
 		slog.Error("Tugas gagal permanen -> DLQ", "file", task.Name)
 		tx := database.DB.Begin()
@@ -168,15 +433,71 @@ func handleFailure(task model.File, err error, cfg *config.Config) {
 	}
 }
 
-func processImageWithReader(reader io.ReadCloser, cfg *config.Config) (io.ReadCloser, error) {
-	pr, pw := io.Pipe()
-	go func() {
+type imageInfo struct {
+	Format           string
+	OriginalWidth    int
+	OriginalHeight   int
+	Width            int
+	Height           int
+	ColourConversion string
+	Quality          int
+}
 
-		defer func() {
-			if err := pw.Close(); err != nil {
-				slog.Warn("Gagal menutup pipe writer", "error", err)
+// encodedOutput adalah satu hasil encode. Goroutine vips menulis output
+// secara berurutan, sehingga reader harus dibaca habis sesuai urutan slice.
+// Width dan Height diisi sebelum byte pertama ditulis ke pipe.
+type encodedOutput struct {
+	Format      outputFormat
+	TargetWidth int
+	Reader      *io.PipeReader
+	Width       int
+	Height      int
+}
+
+func (o *encodedOutput) fileName(name string) string {
+	if o.TargetWidth > 0 {
+		return variantFileName(name, o.TargetWidth, o.Format)
+	}
+	return outputFileName(name, o.Format)
+}
+
+// planOutputs menyusun urutan output: semua format dalam ukuran utama (yang
+// pertama adalah file utama), lalu setiap lebar varian untuk setiap format.
+func planOutputs(cfg *config.Config, profile config.CompressionProfile) []*encodedOutput {
+	formats := configuredFormats(profile)
+	var outputs []*encodedOutput
+	for _, format := range formats {
+		outputs = append(outputs, &encodedOutput{Format: format})
+	}
+	for _, width := range cfg.VariantWidths {
+		for _, format := range formats {
+			outputs = append(outputs, &encodedOutput{Format: format, TargetWidth: width})
+		}
+	}
+	return outputs
+}
+
+// processImageWithReader mengisi info sebelum byte output pertama ditulis ke
+// pipe, sehingga info aman dibaca setelah reader hasil mengembalikan data.
+// Semua output di-encode dari satu decode. Mode akses dipilih loadAccess dari
+// header source.
+func processImageWithReader(reader io.ReadCloser, cfg *config.Config, profile config.CompressionProfile, header sourceHeader) ([]*encodedOutput, *imageInfo, error) {
+	outputs := planOutputs(cfg, profile)
+	writers := make([]*io.PipeWriter, len(outputs))
+	for i, output := range outputs {
+		output.Reader, writers[i] = io.Pipe()
+	}
+	access := loadAccess(cfg, profile, outputs, header)
+
+	info := &imageInfo{}
+	go func() {
+		closeWriters := func(err error) {
+			for _, pw := range writers {
+				pw.CloseWithError(err)
 			}
-		}()
+		}
+
+		defer closeWriters(nil)
 
 		defer func() {
 			if err := reader.Close(); err != nil {
@@ -189,37 +510,144 @@ func processImageWithReader(reader io.ReadCloser, cfg *config.Config) (io.ReadCl
 		defer source.Close()
 
 		img, err := vips.NewImageFromSource(source, &vips.LoadOptions{
-			Access:      vips.AccessSequentialUnbuffered,
+			Access:      access,
+			N:           loadPages(header.Format),
 			FailOnError: true,
 		})
 		if err != nil {
-			pw.CloseWithError(fmt.Errorf("vips load: %w", err))
+			closeWriters(fmt.Errorf("vips load: %w", err))
 			return
 		}
 
 		defer img.Close()
 
-		w, h := img.Width(), img.Height()
-		scale := calculateOptimalScale(w, h, cfg.MaxWidth, cfg.MaxHeight)
-
-		if scale < 1.0 {
-			if err = img.Resize(scale, nil); err != nil {
-				pw.CloseWithError(fmt.Errorf("vips resize: %w", err))
-				return
-			}
+		if err = checkAnimationLimits(img, cfg); err != nil {
+			closeWriters(err)
+			return
+		}
+		animated := isAnimated(img)
+
+		// Orientasi yang tidak terbaca dari header tidak bisa diputar pada
+		// akses sekuensial; tag-nya dibiarkan agar browser yang memutar.
+		if access != vips.AccessRandom && img.Orientation() > 1 {
+			slog.Warn("Orientasi EXIF tidak terdeteksi dari header, gambar tidak diputar", "orientation", img.Orientation())
+		} else if err = normalizeOrientation(img); err != nil {
+			closeWriters(err)
+			return
 		}
 
-		target := vips.NewTarget(pw)
-
-		defer target.Close()
+		w, h := img.Width(), img.PageHeight()
+		if err = fitToProfile(img, profile); err != nil {
+			closeWriters(err)
+			return
+		}
 
-		err = img.WebpsaveTarget(target, &vips.WebpsaveTargetOptions{Q: cfg.WebPQuality})
+		conversion, err := convertToSRGB(img, cfg.KeepDisplayP3)
 		if err != nil {
-			slog.Warn("Gagal menyimpan target webp", "error", err)
+			closeWriters(err)
+			return
+		}
+
+		strip := cfg.MetadataPolicy == "strip"
+		if err = applyMetadataPolicy(img, cfg.MetadataPolicy); err != nil {
+			closeWriters(err)
 			return
 		}
+
+		info.Format = string(img.Format())
+		info.OriginalWidth, info.OriginalHeight = w, h
+		info.Width, info.Height = img.Width(), img.PageHeight()
+		info.ColourConversion = conversion
+
+		scaled := map[int]*vips.Image{}
+		defer func() {
+			for _, variant := range scaled {
+				variant.Close()
+			}
+		}()
+
+		for i, output := range outputs {
+			rendition := img
+			if output.TargetWidth > 0 {
+				if output.TargetWidth >= img.Width() {
+					writers[i].CloseWithError(errRenditionSkipped)
+					continue
+				}
+				rendition, err = scaledCopy(img, output.TargetWidth, scaled)
+				if err != nil {
+					closeWriters(fmt.Errorf("vips resize varian %dw: %w", output.TargetWidth, err))
+					return
+				}
+			}
+			// AVIF tidak menyimpan animasi: file utama AVIF memakai frame
+			// pertama, varian AVIF dari animasi dilewati.
+			if animated && output.Format.Name == "avif" {
+				if i > 0 {
+					writers[i].CloseWithError(errRenditionSkipped)
+					continue
+				}
+				frame, err := firstFrame(rendition)
+				if err != nil {
+					closeWriters(fmt.Errorf("vips frame pertama: %w", err))
+					return
+				}
+				defer frame.Close()
+				rendition = frame
+			}
+			output.Width, output.Height = rendition.Width(), rendition.PageHeight()
+
+			if i == 0 {
+				info.Quality = encodeQuality(output.Format, profile, cfg)
+			}
+			if i == 0 && useAdaptiveQuality(cfg, profile, output.Format, animated) {
+				result, err := searchQuality(rendition, cfg, strip)
+				if err != nil {
+					closeWriters(err)
+					return
+				}
+				slog.Debug("Kualitas adaptif dipilih", "mode", cfg.QualityMode, "quality", result.Quality, "attempts", result.Attempts, "size", len(result.Data), "score", result.Score)
+				// Varian WebP memakai kualitas yang sama dengan file utama.
+				profile.Quality = result.Quality
+				info.Quality = result.Quality
+				if _, err := writers[i].Write(result.Data); err != nil {
+					closeWriters(err)
+					return
+				}
+				writers[i].Close()
+				continue
+			}
+
+			target := vips.NewTarget(writers[i])
+			saveErr := saveImage(rendition, target, output.Format, profile, cfg, strip)
+			if saveErr != nil {
+				slog.Warn("Gagal menyimpan target", "format", output.Format.Name, "width", output.TargetWidth, "error", saveErr)
+				closeWriters(saveErr)
+			}
+			target.Close()
+			if saveErr != nil {
+				return
+			}
+		}
 	}()
-	return pr, nil
+	return outputs, info, nil
+}
+
+// scaledCopy mengecilkan salinan gambar ke lebar tertentu. Hasilnya dipakai
+// ulang untuk semua format dengan lebar yang sama.
+func scaledCopy(img *vips.Image, width int, cache map[int]*vips.Image) (*vips.Image, error) {
+	if variant, ok := cache[width]; ok {
+		return variant, nil
+	}
+	variant, err := img.Copy(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := resizeFrames(variant, float64(width)/float64(img.Width())); err != nil {
+		variant.Close()
+		return nil, err
+	}
+	cache[width] = variant
+	return variant, nil
 }
 
 func calculateOptimalScale(w, h int, maxWidth, maxHeight int) float64 {
@@ -0,0 +1,107 @@
+package compression
+
+import (
+	"bytes"
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/constant"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestExecuteCompressionTaskWithMemoryStorage(t *testing.T) {
+	source := metadataFixture(t)
+	sum := sha256.Sum256(source)
+	sourceHash := hex.EncodeToString(sum[:])
+
+	cfg := &config.Config{
+		DirAttachment:          "post_picture",
+		MetadataPolicy:         "strip",
+		MaxAnimationFrames:     300,
+		MaxAnimationMegapixels: 100,
+		QualityMode:            "fixed",
+		Profiles: map[string]config.CompressionProfile{
+			constant.FileTypeAttachment: {
+				Quality:   80,
+				MaxWidth:  1000,
+				MaxHeight: 1000,
+				Crop:      config.CropFit,
+				Formats:   []string{"webp"},
+			},
+		},
+	}
+	task := model.File{ID: 7, Name: "a.jpg", Type: constant.FileTypeAttachment}
+
+	tests := []struct {
+		name     string
+		reused   *model.File
+		wantPuts int
+	}{
+		{name: "upload", wantPuts: 1},
+		{name: "source identik", reused: &model.File{ID: 3, Name: "b.webp", Type: constant.FileTypeAttachment}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := lookupCompressedSource
+			t.Cleanup(func() { lookupCompressedSource = original })
+
+			var lookedUp string
+			lookupCompressedSource = func(_ context.Context, _ model.File, hash string) (*model.File, error) {
+				lookedUp = hash
+				return tt.reused, nil
+			}
+
+			storage := adapter.NewMemoryStorage(nil)
+			seedSource(t, storage, cfg.ResolvePath(task.Type, task.Name), source)
+
+			output, err := ExecuteCompressionTask(context.Background(), cfg, task, storage)
+			if err != nil {
+				t.Fatalf("ExecuteCompressionTask: %v", err)
+			}
+			if output.SourceHash != sourceHash || lookedUp != sourceHash {
+				t.Errorf("source_hash = %s (lookup %s), want %s", output.SourceHash, lookedUp, sourceHash)
+			}
+
+			opens := storage.CallsByOp(adapter.OpOpen)
+			if len(opens) != 1 || opens[0].Size != int64(len(source)) || opens[0].ContentType != "image/jpeg" {
+				t.Errorf("open = %+v, want satu open %d byte image/jpeg", opens, len(source))
+			}
+
+			puts := storage.CallsByOp(adapter.OpPut)
+			if len(puts) != tt.wantPuts {
+				t.Fatalf("jumlah put = %d, want %d", len(puts), tt.wantPuts)
+			}
+			if tt.reused != nil {
+				if output.Reused != tt.reused {
+					t.Errorf("Reused = %v, want %v", output.Reused, tt.reused)
+				}
+				return
+			}
+
+			put := puts[0]
+			if put.Path != "post_picture/a.webp" || put.ContentType != "image/webp" || put.Size != output.Size || put.Size == 0 {
+				t.Errorf("put = {%s %d %q}, want {post_picture/a.webp %d \"image/webp\"}", put.Path, put.Size, put.ContentType, output.Size)
+			}
+			data, _, _ := storage.Object(put.Path)
+			stored := sha256.Sum256(data)
+			if hex.EncodeToString(stored[:]) != output.ContentHash {
+				t.Errorf("content_hash tidak cocok dengan objek yang disimpan")
+			}
+		})
+	}
+}
+
+// seedSource menaruh source di storage lalu mengosongkan log panggilan agar
+// yang tercatat hanya panggilan dari pipeline.
+func seedSource(t *testing.T, storage *adapter.MemoryStorage, path string, data []byte) {
+	t.Helper()
+
+	if err := storage.Put(context.Background(), path, bytes.NewReader(data), adapter.PutOptions{ContentType: "image/jpeg"}); err != nil {
+		t.Fatalf("seed source: %v", err)
+	}
+	storage.ResetCalls()
+}
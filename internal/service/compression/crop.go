@@ -0,0 +1,71 @@
+package compression
+
+import (
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/vips"
+	"fmt"
+	"math"
+)
+
+// fitToProfile mengecilkan gambar sesuai profil. Mode fit menjaga seluruh
+// gambar di dalam MaxWidth x MaxHeight. Mode lain mengisi kotak CropBox lalu
+// memotongnya: cover dari tengah, attention dan entropy lewat smart crop.
+// Gambar yang lebih kecil tidak diperbesar, tetapi tetap dipotong ke rasio
+// kotak. Untuk animasi ukuran dihitung per frame dan smart crop diganti
+// potongan tengah.
+func fitToProfile(img *vips.Image, profile config.CompressionProfile) error {
+	w, h := img.Width(), img.PageHeight()
+
+	if !profile.IsCover() {
+		scale := calculateOptimalScale(w, h, profile.MaxWidth, profile.MaxHeight)
+		if scale < 1.0 {
+			if err := resizeFrames(img, scale); err != nil {
+				return fmt.Errorf("vips resize: %w", err)
+			}
+		}
+		return nil
+	}
+
+	boxW, boxH := profile.CropBox()
+	scale := math.Min(1.0, math.Max(float64(boxW)/float64(w), float64(boxH)/float64(h)))
+	if scale < 1.0 {
+		if err := resizeFrames(img, scale); err != nil {
+			return fmt.Errorf("vips resize: %w", err)
+		}
+	}
+
+	w, h = img.Width(), img.PageHeight()
+	cropW, cropH := coverArea(w, h, boxW, boxH)
+	if cropW == w && cropH == h {
+		return nil
+	}
+
+	switch {
+	case isAnimated(img):
+		if err := img.ExtractAreaMultiPage((w-cropW)/2, (h-cropH)/2, cropW, cropH); err != nil {
+			return fmt.Errorf("vips crop animasi: %w", err)
+		}
+	case profile.Crop == config.CropAttention || profile.Crop == config.CropEntropy:
+		interesting := vips.InterestingAttention
+		if profile.Crop == config.CropEntropy {
+			interesting = vips.InterestingEntropy
+		}
+		if err := img.Smartcrop(cropW, cropH, &vips.SmartcropOptions{Interesting: interesting}); err != nil {
+			return fmt.Errorf("vips smartcrop: %w", err)
+		}
+	default:
+		if err := img.ExtractArea((w-cropW)/2, (h-cropH)/2, cropW, cropH); err != nil {
+			return fmt.Errorf("vips crop: %w", err)
+		}
+	}
+	return nil
+}
+
+// coverArea menghitung area potong terbesar dengan rasio boxW:boxH yang muat
+// di gambar w x h.
+func coverArea(w, h, boxW, boxH int) (int, int) {
+	ratio := float64(boxW) / float64(boxH)
+	cropW := int(math.Min(float64(w), math.Round(float64(h)*ratio)))
+	cropH := int(math.Min(float64(h), math.Round(float64(w)/ratio)))
+	return max(cropW, 1), max(cropH, 1)
+}
@@ -0,0 +1,56 @@
+package compression
+
+import (
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// lookupCompressedSource dipanggil ExecuteCompressionTask; test menggantinya
+// agar pipeline bisa dijalankan tanpa database.
+var lookupCompressedSource = func(ctx context.Context, task model.File, sourceHash string) (*model.File, error) {
+	return findCompressedSource(database.DB.WithContext(ctx), task, sourceHash)
+}
+
+// findCompressedSource mencari file lain bertipe sama yang source-nya
+// identik dan sudah selesai dikompresi, sehingga upload bisa dilewati.
+func findCompressedSource(tx *gorm.DB, task model.File, sourceHash string) (*model.File, error) {
+	var existing model.File
+	err := tx.Where("source_hash = ? AND type = ? AND status = ? AND id <> ?", sourceHash, task.Type, "compressed", task.ID).
+		Order("id").
+		Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func findDuplicateOutput(tx *gorm.DB, task model.File, contentHash string) (*model.File, error) {
+	var existing model.File
+	err := tx.Where("content_hash = ? AND type = ? AND status = ? AND id <> ?", contentHash, task.Type, "compressed", task.ID).
+		Order("id").
+		Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// countFileReferences menghitung record file lain yang menunjuk objek yang
+// sama. Objek hasil dedup hanya boleh dihapus dari storage jika hasilnya 0.
+func countFileReferences(tx *gorm.DB, fileType, name string, excludeID int32) (int64, error) {
+	var count int64
+	err := tx.Model(&model.File{}).
+		Where("type = ? AND name = ? AND id <> ?", fileType, name, excludeID).
+		Count(&count).Error
+	return count, err
+}
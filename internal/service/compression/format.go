@@ -0,0 +1,73 @@
+package compression
+
+import (
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/vips"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// errRenditionSkipped menutup pipe varian lebar yang tidak dibuat karena
+// gambar sudah lebih kecil dari lebar tersebut.
+var errRenditionSkipped = errors.New("varian dilewati")
+
+type outputFormat struct {
+	Name        string
+	Ext         string
+	ContentType string
+}
+
+var outputFormats = map[string]outputFormat{
+	"webp": {Name: "webp", Ext: ".webp", ContentType: "image/webp"},
+	"avif": {Name: "avif", Ext: ".avif", ContentType: "image/avif"},
+}
+
+// configuredFormats mengembalikan format output profil sesuai urutan. Format
+// pertama adalah file utama yang namanya disimpan di record file.
+func configuredFormats(profile config.CompressionProfile) []outputFormat {
+	formats := make([]outputFormat, 0, len(profile.Formats))
+	for _, name := range profile.Formats {
+		formats = append(formats, outputFormats[name])
+	}
+	return formats
+}
+
+func primaryFormat(profile config.CompressionProfile) outputFormat {
+	return outputFormats[profile.Formats[0]]
+}
+
+func outputFileName(name string, format outputFormat) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + format.Ext
+}
+
+// variantFileName menghasilkan nama varian lebar yang deterministik, misalnya
+// photo@640w.webp, agar API bisa menyusun srcset tanpa query tambahan.
+func variantFileName(name string, width int, format outputFormat) string {
+	return fmt.Sprintf("%s@%dw%s", strings.TrimSuffix(name, filepath.Ext(name)), width, format.Ext)
+}
+
+// saveImage meng-encode gambar ke target. strip membuang semua metadata;
+// delay dan loop animasi tetap ditulis encoder WebP.
+func saveImage(img *vips.Image, target *vips.Target, format outputFormat, profile config.CompressionProfile, cfg *config.Config, strip bool) error {
+	switch format.Name {
+	case "webp":
+		if err := img.WebpsaveTarget(target, &vips.WebpsaveTargetOptions{Q: profile.Quality, Lossless: profile.Lossless, Strip: strip}); err != nil {
+			return fmt.Errorf("vips webpsave: %w", err)
+		}
+	case "avif":
+		if err := img.HeifsaveTarget(target, &vips.HeifsaveTargetOptions{
+			Q:           cfg.AvifQuality,
+			Lossless:    profile.Lossless,
+			Compression: vips.HeifCompressionAv1,
+			Effort:      cfg.AvifEffort,
+			Strip:       strip,
+		}); err != nil {
+			return fmt.Errorf("vips heifsave: %w", err)
+		}
+	default:
+		return fmt.Errorf("format output tidak dikenal: %s", format.Name)
+	}
+	return nil
+}
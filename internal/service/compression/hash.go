@@ -0,0 +1,47 @@
+package compression
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// hashingReader menghitung SHA-256 dari byte yang melewatinya. Close sengaja
+// tidak menutup reader asli agar pemanggil masih bisa menguras sisa data
+// (drain) setelah decoder berhenti membaca.
+type hashingReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+func newHashingReader(reader io.Reader) *hashingReader {
+	return &hashingReader{reader: reader, hash: sha256.New()}
+}
+
+func (h *hashingReader) Read(p []byte) (int, error) {
+	n, err := h.reader.Read(p)
+	if n > 0 {
+		h.hash.Write(p[:n])
+		h.size += int64(n)
+	}
+	return n, err
+}
+
+func (h *hashingReader) Close() error {
+	return nil
+}
+
+func (h *hashingReader) drain() error {
+	_, err := io.Copy(io.Discard, h)
+	return err
+}
+
+func (h *hashingReader) Sum() string {
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+func (h *hashingReader) Size() int64 {
+	return h.size
+}
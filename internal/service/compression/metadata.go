@@ -0,0 +1,97 @@
+package compression
+
+import (
+	"chrononews-scheduler/vips"
+	"fmt"
+)
+
+// copyrightFields adalah tag EXIF yang dipertahankan oleh policy
+// keep-copyright. Nilainya disimpan dalam format string libvips dan ditulis
+// ulang ke EXIF saat encode.
+var copyrightFields = []string{"exif-ifd0-Copyright", "exif-ifd0-Artist"}
+
+// animationFields adalah field yang dipertahankan removeMetadata pada animasi:
+// field yang juga dipertahankan RemoveExif, ditambah delay dan loop.
+var animationFields = map[string]bool{
+	"icc-profile-data":  true,
+	"orientation":       true,
+	"n-pages":           true,
+	"page-height":       true,
+	"palette-bit-depth": true,
+	"delay":             true,
+	"loop":              true,
+	"gif-delay":         true,
+	"gif-loop":          true,
+	"background":        true,
+}
+
+// normalizeOrientation memutar gambar sesuai tag orientasi EXIF lalu
+// menghapus tag tersebut, sehingga output tidak diputar dua kali oleh
+// browser.
+func normalizeOrientation(img *vips.Image) error {
+	if img.Orientation() <= 1 || isAnimated(img) {
+		return nil
+	}
+	if err := img.Autorot(); err != nil {
+		return fmt.Errorf("vips autorot: %w", err)
+	}
+	return nil
+}
+
+// applyMetadataPolicy menyiapkan metadata sebelum encode. Policy strip
+// ditangani encoder lewat opsi Strip. keep-icc dan keep-copyright membuang
+// EXIF, XMP dan IPTC (termasuk GPS); keep-icc hanya menyisakan profil ICC,
+// keep-copyright hanya menyisakan tag hak cipta dan pembuat.
+func applyMetadataPolicy(img *vips.Image, policy string) error {
+	if policy == "strip" {
+		return nil
+	}
+
+	kept := map[string]string{}
+	if policy == "keep-copyright" {
+		for _, field := range copyrightFields {
+			if !img.HasField(field) {
+				continue
+			}
+			value, err := img.GetString(field)
+			if err != nil {
+				return fmt.Errorf("gagal membaca metadata %s: %w", field, err)
+			}
+			kept[field] = value
+		}
+	}
+
+	if err := removeMetadata(img); err != nil {
+		return err
+	}
+	if policy == "keep-copyright" {
+		if err := img.RemoveICCProfile(); err != nil {
+			return fmt.Errorf("vips remove icc: %w", err)
+		}
+	}
+	for field, value := range kept {
+		img.SetString(field, value)
+	}
+	return nil
+}
+
+// removeMetadata membuang EXIF, XMP dan IPTC tetapi mempertahankan profil
+// ICC. RemoveExif ikut menghapus delay dan loop, sehingga animasi dibersihkan
+// per field.
+func removeMetadata(img *vips.Image) error {
+	if !isAnimated(img) {
+		if err := img.RemoveExif(); err != nil {
+			return fmt.Errorf("vips remove exif: %w", err)
+		}
+		return nil
+	}
+	for _, field := range img.GetFields() {
+		if animationFields[field] {
+			continue
+		}
+		if err := img.RemoveField(field); err != nil {
+			return fmt.Errorf("vips remove %s: %w", field, err)
+		}
+	}
+	return nil
+}
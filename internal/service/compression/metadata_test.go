@@ -0,0 +1,357 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/vips"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	fixtureArtist    = "Jane Doe"
+	fixtureCopyright = "ChronoNews"
+	fixtureICCDesc   = "sRGB test profile"
+)
+
+func TestMain(m *testing.M) {
+	vips.Startup(nil)
+	code := m.Run()
+	vips.Shutdown()
+	os.Exit(code)
+}
+
+func TestInspectHeaderReadsJPEGOrientation(t *testing.T) {
+	source := metadataFixture(t)
+
+	header := inspectHeader(bufio.NewReaderSize(bytes.NewReader(source), headerPeekSize), "jpeg")
+	if header.Orientation != 6 {
+		t.Fatalf("orientation = %d, want 6", header.Orientation)
+	}
+}
+
+func TestMetadataPolicies(t *testing.T) {
+	tests := []struct {
+		policy        string
+		wantICC       bool
+		wantCopyright bool
+	}{
+		{policy: "strip"},
+		{policy: "keep-icc", wantICC: true},
+		{policy: "keep-copyright", wantCopyright: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			out := compressFixture(t, tt.policy)
+			defer out.Close()
+
+			for _, field := range out.GetFields() {
+				if strings.HasPrefix(field, "exif-ifd3-") || strings.Contains(field, "GPS") {
+					t.Errorf("GPS tag %s masih ada di output", field)
+				}
+			}
+
+			profile, hasICC := out.GetICCProfile()
+			hasICC = hasICC && len(profile) > 0
+			if hasICC != tt.wantICC {
+				t.Errorf("profil ICC ada = %v, want %v", hasICC, tt.wantICC)
+			}
+			if hasICC && iccDescription(profile) != fixtureICCDesc {
+				t.Errorf("deskripsi ICC = %q, want %q", iccDescription(profile), fixtureICCDesc)
+			}
+
+			for field, want := range map[string]string{
+				"exif-ifd0-Artist":    fixtureArtist,
+				"exif-ifd0-Copyright": fixtureCopyright,
+			} {
+				value, err := out.GetString(field)
+				found := out.HasField(field) && err == nil && strings.HasPrefix(value, want)
+				if found != tt.wantCopyright {
+					t.Errorf("%s ada = %v (%q), want %v", field, found, value, tt.wantCopyright)
+				}
+			}
+
+			assertUpright(t, out)
+		})
+	}
+}
+
+func TestAnimatedOutputKeepsICCAndTiming(t *testing.T) {
+	source := animatedFixture(t)
+	cfg := &config.Config{
+		MetadataPolicy:         "keep-icc",
+		MaxAnimationFrames:     300,
+		MaxAnimationMegapixels: 100,
+		QualityMode:            "fixed",
+	}
+	profile := config.CompressionProfile{
+		Quality:   90,
+		MaxWidth:  1000,
+		MaxHeight: 1000,
+		Crop:      config.CropFit,
+		Formats:   []string{"webp"},
+	}
+	header := inspectHeader(bufio.NewReaderSize(bytes.NewReader(source), headerPeekSize), "webp")
+
+	outputs, _, err := processImageWithReader(io.NopCloser(bytes.NewReader(source)), cfg, profile, header)
+	if err != nil {
+		t.Fatalf("processImageWithReader: %v", err)
+	}
+	data, err := io.ReadAll(outputs[0].Reader)
+	if err != nil {
+		t.Fatalf("membaca output: %v", err)
+	}
+
+	options := vips.DefaultLoadOptions()
+	options.N = -1
+	out, err := vips.NewImageFromBuffer(data, options)
+	if err != nil {
+		t.Fatalf("memuat output: %v", err)
+	}
+	defer out.Close()
+
+	if out.Pages() != 2 {
+		t.Errorf("jumlah frame = %d, want 2", out.Pages())
+	}
+	if loop, err := out.GetInt("loop"); err != nil || loop != 3 {
+		t.Errorf("loop = %d (%v), want 3", loop, err)
+	}
+	if profile, ok := out.GetICCProfile(); !ok || iccDescription(profile) != fixtureICCDesc {
+		t.Errorf("profil ICC animasi hilang")
+	}
+	for _, field := range out.GetFields() {
+		if strings.HasPrefix(field, "exif-ifd3-") || strings.Contains(field, "GPS") {
+			t.Errorf("GPS tag %s masih ada di output animasi", field)
+		}
+	}
+}
+
+// animatedFixture menyusun WebP animasi dua frame dari metadataFixture,
+// lengkap dengan EXIF, profil ICC dan loop 3.
+func animatedFixture(t *testing.T) []byte {
+	t.Helper()
+
+	var frames []*vips.Image
+	for i := 0; i < 2; i++ {
+		frame, err := vips.NewImageFromBuffer(metadataFixture(t), nil)
+		if err != nil {
+			t.Fatalf("memuat frame: %v", err)
+		}
+		defer frame.Close()
+		frames = append(frames, frame)
+	}
+
+	strip, err := vips.NewArrayjoin(frames, &vips.ArrayjoinOptions{Across: 1})
+	if err != nil {
+		t.Fatalf("arrayjoin: %v", err)
+	}
+	defer strip.Close()
+	strip.SetPageHeight(frames[0].Height())
+	strip.SetInt("loop", 3)
+
+	data, err := strip.WebpsaveBuffer(&vips.WebpsaveBufferOptions{Q: 90, PageHeight: frames[0].Height()})
+	if err != nil {
+		t.Fatalf("webpsave animasi: %v", err)
+	}
+	return data
+}
+
+// compressFixture menjalankan fixture lewat pipeline kompresi dengan policy
+// tertentu lalu memuat ulang output WebP-nya.
+func compressFixture(t *testing.T, policy string) *vips.Image {
+	t.Helper()
+
+	source := metadataFixture(t)
+	cfg := &config.Config{
+		MetadataPolicy:         policy,
+		MaxAnimationFrames:     300,
+		MaxAnimationMegapixels: 100,
+		QualityMode:            "fixed",
+	}
+	profile := config.CompressionProfile{
+		Quality:   90,
+		MaxWidth:  1000,
+		MaxHeight: 1000,
+		Crop:      config.CropFit,
+		Formats:   []string{"webp"},
+	}
+	header := inspectHeader(bufio.NewReaderSize(bytes.NewReader(source), headerPeekSize), "jpeg")
+
+	outputs, _, err := processImageWithReader(io.NopCloser(bytes.NewReader(source)), cfg, profile, header)
+	if err != nil {
+		t.Fatalf("processImageWithReader: %v", err)
+	}
+	data, err := io.ReadAll(outputs[0].Reader)
+	if err != nil {
+		t.Fatalf("membaca output: %v", err)
+	}
+
+	out, err := vips.NewImageFromBuffer(data, nil)
+	if err != nil {
+		t.Fatalf("memuat output: %v", err)
+	}
+	return out
+}
+
+// assertUpright memeriksa bahwa fixture 40x20 dengan Orientation=6 sudah
+// diputar 90 derajat searah jarum jam: separuh kiri (merah) menjadi separuh
+// atas dan separuh kanan (biru) menjadi separuh bawah.
+func assertUpright(t *testing.T, out *vips.Image) {
+	t.Helper()
+
+	if out.Width() != 20 || out.Height() != 40 {
+		t.Fatalf("ukuran output = %dx%d, want 20x40", out.Width(), out.Height())
+	}
+	if orientation := out.Orientation(); orientation > 1 {
+		t.Errorf("tag orientasi output = %d, want 1 atau tidak ada", orientation)
+	}
+
+	top, err := out.Getpoint(10, 5)
+	if err != nil {
+		t.Fatalf("getpoint atas: %v", err)
+	}
+	bottom, err := out.Getpoint(10, 35)
+	if err != nil {
+		t.Fatalf("getpoint bawah: %v", err)
+	}
+	if top[0] < 150 || top[2] > 100 {
+		t.Errorf("piksel atas = %v, want merah", top)
+	}
+	if bottom[2] < 150 || bottom[0] > 100 {
+		t.Errorf("piksel bawah = %v, want biru", bottom)
+	}
+}
+
+// metadataFixture membuat JPEG 40x20 (kiri merah, kanan biru) dengan EXIF
+// Orientation=6, Artist, Copyright dan GPS, serta profil ICC bernama sRGB
+// agar tidak dikonversi.
+func metadataFixture(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg encode: %v", err)
+	}
+
+	exif := append([]byte("Exif\x00\x00"), fixtureExif()...)
+	icc := append([]byte("ICC_PROFILE\x00\x01\x01"), fixtureICC()...)
+
+	var out bytes.Buffer
+	out.Write(encoded.Bytes()[:2])
+	writeJPEGSegment(&out, 0xE1, exif)
+	writeJPEGSegment(&out, 0xE2, icc)
+	out.Write(encoded.Bytes()[2:])
+	return out.Bytes()
+}
+
+func writeJPEGSegment(out *bytes.Buffer, marker byte, payload []byte) {
+	out.Write([]byte{0xFF, marker})
+	binary.Write(out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+}
+
+type tiffEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+// fixtureExif menyusun blok TIFF little-endian dengan IFD0 dan IFD GPS.
+func fixtureExif() []byte {
+	const headerSize = 8
+	ascii := func(s string) []byte { return append([]byte(s), 0) }
+	short := func(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+	long := func(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+
+	ifd0 := []tiffEntry{
+		{tag: exifOrientationTag, kind: 3, count: 1, value: short(6)},
+		{tag: 0x013B, kind: 2, count: uint32(len(fixtureArtist) + 1), value: ascii(fixtureArtist)},
+		{tag: 0x8298, kind: 2, count: uint32(len(fixtureCopyright) + 1), value: ascii(fixtureCopyright)},
+		{tag: 0x8825, kind: 4, count: 1},
+	}
+	gps := []tiffEntry{
+		{tag: 0x0000, kind: 1, count: 4, value: []byte{2, 3, 0, 0}},
+		{tag: 0x0001, kind: 2, count: 2, value: ascii("S")},
+	}
+
+	ifd0Size := 2 + len(ifd0)*12 + 4
+	var ifd0Data []byte
+	for _, entry := range ifd0 {
+		if len(entry.value) > 4 {
+			ifd0Data = append(ifd0Data, entry.value...)
+			if len(ifd0Data)%2 == 1 {
+				ifd0Data = append(ifd0Data, 0)
+			}
+		}
+	}
+	gpsOffset := headerSize + ifd0Size + len(ifd0Data)
+	ifd0[3].value = long(uint32(gpsOffset))
+
+	out := []byte("II*\x00")
+	out = binary.LittleEndian.AppendUint32(out, headerSize)
+	out = appendIFD(out, ifd0, headerSize+ifd0Size)
+	out = append(out, ifd0Data...)
+	return appendIFD(out, gps, gpsOffset+2+len(gps)*12+4)
+}
+
+// appendIFD menulis entri IFD; nilai lebih dari 4 byte ditaruh berurutan
+// mulai dataOffset oleh pemanggil.
+func appendIFD(out []byte, entries []tiffEntry, dataOffset int) []byte {
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(entries)))
+	for _, entry := range entries {
+		out = binary.LittleEndian.AppendUint16(out, entry.tag)
+		out = binary.LittleEndian.AppendUint16(out, entry.kind)
+		out = binary.LittleEndian.AppendUint32(out, entry.count)
+		if len(entry.value) > 4 {
+			out = binary.LittleEndian.AppendUint32(out, uint32(dataOffset))
+			dataOffset += len(entry.value) + len(entry.value)%2
+			continue
+		}
+		value := make([]byte, 4)
+		copy(value, entry.value)
+		out = append(out, value...)
+	}
+	return binary.LittleEndian.AppendUint32(out, 0)
+}
+
+// fixtureICC menyusun profil ICC v2 minimal dengan satu tag desc.
+func fixtureICC() []byte {
+	desc := []byte("desc\x00\x00\x00\x00")
+	desc = binary.BigEndian.AppendUint32(desc, uint32(len(fixtureICCDesc)+1))
+	desc = append(desc, fixtureICCDesc...)
+	desc = append(desc, 0)
+	desc = append(desc, make([]byte, 4+4+2+1+67)...)
+
+	const tagTableSize = 4 + 12
+	profile := make([]byte, 128)
+	copy(profile[4:], "lcms")
+	binary.BigEndian.PutUint32(profile[8:], 0x02100000)
+	copy(profile[12:], "mntrRGB XYZ ")
+	copy(profile[36:], "acsp")
+	profile = binary.BigEndian.AppendUint32(profile, 1)
+	profile = append(profile, "desc"...)
+	profile = binary.BigEndian.AppendUint32(profile, 128+tagTableSize)
+	profile = binary.BigEndian.AppendUint32(profile, uint32(len(desc)))
+	profile = append(profile, desc...)
+	binary.BigEndian.PutUint32(profile[0:], uint32(len(profile)))
+	return profile
+}
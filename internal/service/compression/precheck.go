@@ -0,0 +1,97 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const sniffLength = 16
+
+// errSourceRejected menandai source yang tidak akan pernah bisa diproses.
+// Tugasnya langsung diberi status "rejected" tanpa memakai jatah retry.
+var errSourceRejected = errors.New("source ditolak")
+
+type imageSignature struct {
+	format string
+	offset int
+	magic  []byte
+}
+
+var imageSignatures = []imageSignature{
+	{format: "jpeg", magic: []byte{0xFF, 0xD8, 0xFF}},
+	{format: "png", magic: []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}},
+	{format: "gif", magic: []byte("GIF87a")},
+	{format: "gif", magic: []byte("GIF89a")},
+	{format: "webp", offset: 8, magic: []byte("WEBP")},
+	{format: "tiff", magic: []byte{'I', 'I', 0x2A, 0x00}},
+	{format: "tiff", magic: []byte{'M', 'M', 0x00, 0x2A}},
+	{format: "heif", offset: 4, magic: []byte("ftyp")},
+}
+
+// heifBrands membedakan HEIC/AVIF dari container ISO-BMFF lain seperti MP4.
+var heifBrands = [][]byte{
+	[]byte("heic"), []byte("heix"), []byte("mif1"), []byte("msf1"), []byte("avif"), []byte("avis"),
+}
+
+// precheckSource menolak source yang melebihi COMPRESSION_MAX_SOURCE_SIZE_MB
+// hanya dengan Stat (HEAD di S3), sebelum body-nya diunduh.
+func precheckSource(ctx context.Context, cfg *config.Config, storage adapter.Storage, path string) error {
+	if cfg.MaxSourceSizeMB <= 0 {
+		return nil
+	}
+	info, err := storage.Stat(ctx, path)
+	if err != nil {
+		return fmt.Errorf("gagal membaca info source (%s): %w", path, err)
+	}
+	limit := int64(cfg.MaxSourceSizeMB) * 1024 * 1024
+	if info.Size > limit {
+		return fmt.Errorf("%w: ukuran %d byte melebihi batas %d MB", errSourceRejected, info.Size, cfg.MaxSourceSizeMB)
+	}
+	return nil
+}
+
+// sniffImage mencocokkan byte awal source dengan format gambar yang didukung
+// tanpa mengonsumsi reader.
+func sniffImage(reader *bufio.Reader) (string, error) {
+	head, err := reader.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("gagal membaca source: %w", err)
+	}
+	if len(head) == 0 {
+		return "", fmt.Errorf("%w: source kosong", errSourceRejected)
+	}
+
+	for _, sig := range imageSignatures {
+		end := sig.offset + len(sig.magic)
+		if len(head) < end || !bytes.Equal(head[sig.offset:end], sig.magic) {
+			continue
+		}
+		switch sig.format {
+		case "webp":
+			if !bytes.HasPrefix(head, []byte("RIFF")) {
+				continue
+			}
+		case "heif":
+			if len(head) < 12 || !containsBrand(head[8:12]) {
+				continue
+			}
+		}
+		return sig.format, nil
+	}
+	return "", fmt.Errorf("%w: bukan file gambar yang didukung (byte awal %x)", errSourceRejected, head)
+}
+
+func containsBrand(brand []byte) bool {
+	for _, known := range heifBrands {
+		if bytes.Equal(brand, known) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSniffImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		head    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "jpeg", head: []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F'}, want: "jpeg"},
+		{name: "png", head: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), want: "png"},
+		{name: "gif", head: []byte("GIF89a\x01\x00\x01\x00"), want: "gif"},
+		{name: "webp", head: []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), want: "webp"},
+		{name: "tiff", head: []byte("II*\x00\x08\x00\x00\x00"), want: "tiff"},
+		{name: "heic", head: []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"), want: "heif"},
+		{name: "avif", head: []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00"), want: "heif"},
+		{name: "mp4", head: []byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00"), wantErr: true},
+		{name: "wav", head: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), wantErr: true},
+		{name: "webp tanpa RIFF", head: []byte("XXXX\x24\x00\x00\x00WEBPVP8 "), wantErr: true},
+		{name: "html", head: []byte("<!DOCTYPE html><html>"), wantErr: true},
+		{name: "kosong", head: nil, wantErr: true},
+		{name: "pendek", head: []byte{0xFF, 0xD8}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReaderSize(bytes.NewReader(tt.head), headerPeekSize)
+			got, err := sniffImage(reader)
+			if tt.wantErr {
+				if !errors.Is(err, errSourceRejected) {
+					t.Errorf("sniffImage = %q, %v; want errSourceRejected", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("sniffImage = %q, %v; want %q", got, err, tt.want)
+			}
+
+			// Sniffing tidak boleh mengonsumsi reader.
+			rest, _ := io.ReadAll(reader)
+			if !bytes.Equal(rest, tt.head) {
+				t.Errorf("reader terkonsumsi: sisa %x, want %x", rest, tt.head)
+			}
+		})
+	}
+}
+
+func TestPrecheckSourceSizeLimit(t *testing.T) {
+	ctx := context.Background()
+	storage := adapter.NewMemoryStorage(nil)
+	storage.Put(ctx, "small.jpg", bytes.NewReader(make([]byte, 1024*1024)), adapter.PutOptions{})
+	storage.Put(ctx, "large.jpg", bytes.NewReader(make([]byte, 1024*1024+1)), adapter.PutOptions{})
+
+	cfg := &config.Config{MaxSourceSizeMB: 1}
+	if err := precheckSource(ctx, cfg, storage, "small.jpg"); err != nil {
+		t.Errorf("source tepat di batas ditolak: %v", err)
+	}
+	if err := precheckSource(ctx, cfg, storage, "large.jpg"); !errors.Is(err, errSourceRejected) {
+		t.Errorf("source di atas batas: err = %v, want errSourceRejected", err)
+	}
+	if err := precheckSource(ctx, cfg, storage, "missing.jpg"); err == nil || errors.Is(err, errSourceRejected) {
+		t.Errorf("source hilang: err = %v, want error biasa (bisa di-retry)", err)
+	}
+	if calls := storage.CallsByOp(adapter.OpOpen); len(calls) != 0 {
+		t.Errorf("precheck membuka body source %d kali, want hanya Stat", len(calls))
+	}
+
+	if err := precheckSource(ctx, &config.Config{}, storage, "missing.jpg"); err != nil {
+		t.Errorf("tanpa batas ukuran: err = %v, want nil tanpa Stat", err)
+	}
+}
@@ -0,0 +1,289 @@
+package compression
+
+import (
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/vips"
+	"fmt"
+	"log/slog"
+)
+
+// Konstanta stabilisasi SSIM untuk piksel 8-bit: (0.01*255)^2 dan
+// (0.03*255)^2.
+const (
+	ssimC1    = 6.5025
+	ssimC2    = 58.5225
+	ssimSigma = 1.5
+)
+
+type qualityResult struct {
+	Quality  int
+	Data     []byte
+	Score    float64
+	Attempts int
+}
+
+// encodeQuality mengembalikan kualitas encode file utama untuk disimpan di
+// record file. Output lossless tidak punya nilai kualitas.
+func encodeQuality(format outputFormat, profile config.CompressionProfile, cfg *config.Config) int {
+	switch {
+	case profile.Lossless:
+		return 0
+	case format.Name == "avif":
+		return cfg.AvifQuality
+	default:
+		return profile.Quality
+	}
+}
+
+// useAdaptiveQuality melaporkan apakah kualitas file utama dicari per gambar.
+// Pencarian hanya untuk WebP lossy yang tidak beranimasi; AVIF tetap memakai
+// COMPRESSION_AVIF_QUALITY.
+func useAdaptiveQuality(cfg *config.Config, profile config.CompressionProfile, format outputFormat, animated bool) bool {
+	return cfg.QualityMode != "fixed" && format.Name == "webp" && !profile.Lossless && !animated
+}
+
+// searchQuality mencari kualitas WebP dengan binary search di antara
+// COMPRESSION_ADAPTIVE_MIN_QUALITY dan COMPRESSION_ADAPTIVE_MAX_QUALITY,
+// paling banyak COMPRESSION_ADAPTIVE_MAX_ATTEMPTS kali encode. Mode ssim
+// memilih kualitas terendah yang mencapai COMPRESSION_TARGET_SSIM; mode size
+// memilih kualitas tertinggi yang muat di COMPRESSION_TARGET_SIZE_KB. Bila
+// tidak ada yang memenuhi target, hasil yang paling dekat yang dipakai.
+func searchQuality(img *vips.Image, cfg *config.Config, strip bool) (qualityResult, error) {
+	var reference *ssimReference
+	if cfg.QualityMode == "ssim" {
+		var err error
+		if reference, err = newSSIMReference(img); err != nil {
+			return qualityResult{}, fmt.Errorf("vips ssim: %w", err)
+		}
+		defer reference.Close()
+	}
+	sizeLimit := cfg.TargetSizeKB * 1024
+
+	var best, closest *qualityResult
+	low, high := cfg.AdaptiveMinQuality, cfg.AdaptiveMaxQuality
+	attempts := 0
+	for attempts < cfg.AdaptiveMaxAttempts && low <= high {
+		attempts++
+		quality := (low + high) / 2
+		data, err := img.WebpsaveBuffer(&vips.WebpsaveBufferOptions{Q: quality, Strip: strip})
+		if err != nil {
+			return qualityResult{}, fmt.Errorf("vips webpsave q=%d: %w", quality, err)
+		}
+		result := &qualityResult{Quality: quality, Data: data}
+
+		var meetsTarget bool
+		if reference != nil {
+			if result.Score, err = reference.Score(data); err != nil {
+				return qualityResult{}, fmt.Errorf("vips ssim q=%d: %w", quality, err)
+			}
+			meetsTarget = result.Score >= cfg.TargetSSIM
+			if closest == nil || result.Score > closest.Score {
+				closest = result
+			}
+		} else {
+			meetsTarget = len(data) <= sizeLimit
+			if closest == nil || len(data) < len(closest.Data) {
+				closest = result
+			}
+		}
+		slog.Debug("Percobaan kualitas adaptif", "mode", cfg.QualityMode, "quality", quality, "size", len(data), "score", result.Score)
+
+		// Mode ssim mencari kualitas terendah yang lolos, mode size yang
+		// tertinggi.
+		switch {
+		case meetsTarget && reference != nil:
+			best, high = result, quality-1
+		case meetsTarget:
+			best, low = result, quality+1
+		case reference != nil:
+			low = quality + 1
+		default:
+			high = quality - 1
+		}
+	}
+
+	if best == nil {
+		best = closest
+		slog.Warn("Target kualitas adaptif tidak tercapai, memakai hasil terdekat", "mode", cfg.QualityMode, "quality", best.Quality, "attempts", attempts)
+	}
+	best.Attempts = attempts
+	return *best, nil
+}
+
+// ssimReference menyimpan bagian perhitungan SSIM milik gambar acuan agar
+// tidak dihitung ulang di setiap percobaan. SSIM dihitung pada kanal luma
+// dengan jendela Gaussian, lalu dirata-rata ke satu skor 0..1.
+type ssimReference struct {
+	luma    *vips.Image
+	mean    *vips.Image
+	meanSq  *vips.Image
+	sqBlur  *vips.Image
+	cleanup []*vips.Image
+}
+
+func newSSIMReference(img *vips.Image) (*ssimReference, error) {
+	ref := &ssimReference{}
+	var err error
+	if ref.luma, err = ref.track(lumaOf(img)); err != nil {
+		ref.Close()
+		return nil, err
+	}
+	if ref.mean, err = ref.track(blurOf(ref.luma)); err != nil {
+		ref.Close()
+		return nil, err
+	}
+	if ref.meanSq, err = ref.track(productOf(ref.mean, ref.mean)); err != nil {
+		ref.Close()
+		return nil, err
+	}
+	square, err := ref.track(productOf(ref.luma, ref.luma))
+	if err != nil {
+		ref.Close()
+		return nil, err
+	}
+	if ref.sqBlur, err = ref.track(blurOf(square)); err != nil {
+		ref.Close()
+		return nil, err
+	}
+	return ref, nil
+}
+
+// Score men-decode hasil encode lalu menghitung SSIM terhadap acuan.
+func (r *ssimReference) Score(data []byte) (float64, error) {
+	decoded, err := vips.NewImageFromBuffer(data, nil)
+	if err != nil {
+		return 0, err
+	}
+	temps := []*vips.Image{decoded}
+	defer func() {
+		for _, img := range temps {
+			img.Close()
+		}
+	}()
+	step := func(img *vips.Image, err error) (*vips.Image, error) {
+		if img != nil {
+			temps = append(temps, img)
+		}
+		return img, err
+	}
+
+	luma, err := step(lumaOf(decoded))
+	if err != nil {
+		return 0, err
+	}
+	mean, err := step(blurOf(luma))
+	if err != nil {
+		return 0, err
+	}
+	meanSq, err := step(productOf(mean, mean))
+	if err != nil {
+		return 0, err
+	}
+	meanCross, err := step(productOf(r.mean, mean))
+	if err != nil {
+		return 0, err
+	}
+	square, err := step(productOf(luma, luma))
+	if err != nil {
+		return 0, err
+	}
+	sqBlur, err := step(blurOf(square))
+	if err != nil {
+		return 0, err
+	}
+	cross, err := step(productOf(r.luma, luma))
+	if err != nil {
+		return 0, err
+	}
+	crossBlur, err := step(blurOf(cross))
+	if err != nil {
+		return 0, err
+	}
+
+	// sigma1^2 + sigma2^2 = blur(x^2) + blur(y^2) - mu1^2 - mu2^2
+	// sigma12 = blur(xy) - mu1*mu2
+	variance, err := step(r.sqBlur.Copy(nil))
+	if err != nil {
+		return 0, err
+	}
+	for _, apply := range []func() error{
+		func() error { return variance.Add(sqBlur) },
+		func() error { return variance.Subtract(r.meanSq) },
+		func() error { return variance.Subtract(meanSq) },
+		func() error { return variance.Linear([]float64{1}, []float64{ssimC2}, nil) },
+		func() error { return crossBlur.Subtract(meanCross) },
+		func() error { return crossBlur.Linear([]float64{2}, []float64{ssimC2}, nil) },
+	} {
+		if err := apply(); err != nil {
+			return 0, err
+		}
+	}
+
+	numerator, err := step(meanCross.Copy(nil))
+	if err != nil {
+		return 0, err
+	}
+	denominator, err := step(r.meanSq.Copy(nil))
+	if err != nil {
+		return 0, err
+	}
+	for _, apply := range []func() error{
+		func() error { return numerator.Linear([]float64{2}, []float64{ssimC1}, nil) },
+		func() error { return numerator.Multiply(crossBlur) },
+		func() error { return denominator.Add(meanSq) },
+		func() error { return denominator.Linear([]float64{1}, []float64{ssimC1}, nil) },
+		func() error { return denominator.Multiply(variance) },
+		func() error { return numerator.Divide(denominator) },
+	} {
+		if err := apply(); err != nil {
+			return 0, err
+		}
+	}
+	return numerator.Avg()
+}
+
+func (r *ssimReference) track(img *vips.Image, err error) (*vips.Image, error) {
+	if img != nil {
+		r.cleanup = append(r.cleanup, img)
+	}
+	return img, err
+}
+
+func (r *ssimReference) Close() {
+	for _, img := range r.cleanup {
+		img.Close()
+	}
+}
+
+// lumaOf mengembalikan salinan satu kanal abu-abu bertipe float, tanpa alpha.
+func lumaOf(img *vips.Image) (*vips.Image, error) {
+	luma, err := img.Copy(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := luma.Colourspace(vips.InterpretationBW, nil); err != nil {
+		return luma, err
+	}
+	if luma.Bands() > 1 {
+		if err := luma.ExtractBand(0, nil); err != nil {
+			return luma, err
+		}
+	}
+	return luma, luma.Cast(vips.BandFormatFloat, nil)
+}
+
+func blurOf(img *vips.Image) (*vips.Image, error) {
+	blurred, err := img.Copy(nil)
+	if err != nil {
+		return nil, err
+	}
+	return blurred, blurred.Gaussblur(ssimSigma, nil)
+}
+
+func productOf(left, right *vips.Image) (*vips.Image, error) {
+	product, err := left.Copy(nil)
+	if err != nil {
+		return nil, err
+	}
+	return product, product.Multiply(right)
+}
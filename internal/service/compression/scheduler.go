@@ -16,11 +16,14 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func RunScheduler(ctx context.Context, cfg *config.Config, storage *adapter.StorageAdapter) {
+func RunScheduler(ctx context.Context, cfg *config.Config, storage adapter.Storage) {
 	slog.Info("Scheduler dimulai.")
 
 	if cfg.IsTestMode {
-		slog.Warn("TEST MODE AKTIF: Hanya simulasi. File tidak disimpan & DB tidak diupdate. (Set LOG_LEVEL=debug untuk detail).")
+		slog.Warn("TEST MODE AKTIF: Hanya simulasi. Hasil disimpan di memori & DB tidak diupdate. (Set LOG_LEVEL=debug untuk detail).")
+		sink := adapter.NewMemoryStorage(storage)
+		storage = sink
+		defer logTestModeResults(sink)
 	}
 
 	mode := "Sekuensial"
@@ -132,6 +135,25 @@ func monitorPeakRAM(p *process.Process, done <-chan struct{}) (peakRAM uint64) {
 	}
 }
 
+func logTestModeResults(sink *adapter.MemoryStorage) {
+	for _, call := range sink.CallsByOp(adapter.OpPut) {
+		slog.Debug("TEST MODE: Hasil tersimpan di memori",
+			"path", call.Path,
+			"size_bytes", call.Size,
+			"content_type", call.ContentType,
+			"error", call.Err,
+		)
+	}
+
+	puts := sink.CallStats(adapter.OpPut)
+	slog.Info("TEST MODE: Ringkasan hasil di memori",
+		"jumlah_put", puts.Count,
+		"jumlah_open", sink.CallStats(adapter.OpOpen).Count,
+		"jumlah_delete", sink.CallStats(adapter.OpDelete).Count,
+		"total_bytes", puts.Bytes,
+	)
+}
+
 func logResourceUsage(duration time.Duration, cpuTimeBefore, cpuTimeAfter float64, peakRAM uint64) {
 	cpuTimeUsed := cpuTimeAfter - cpuTimeBefore
 	cpuPercent := 0.0
@@ -8,7 +8,7 @@ import (
 	"log/slog"
 )
 
-func runSequential(ctx context.Context, tasks []model.File, cfg *config.Config, storage *adapter.StorageAdapter) {
+func runSequential(ctx context.Context, tasks []model.File, cfg *config.Config, storage adapter.Storage) {
 	var successfulCount int
 	var failedCount int
 
@@ -26,7 +26,7 @@ func runSequential(ctx context.Context, tasks []model.File, cfg *config.Config,
 
 		slog.Debug("Memproses file", "mode", "sekuensial", "file_name", task.Name)
 
-		err := ExecuteCompressionTask(ctx, cfg, task, storage)
+		output, err := ExecuteCompressionTask(ctx, cfg, task, storage)
 
 		if err != nil {
 			failedCount++
@@ -34,7 +34,7 @@ func runSequential(ctx context.Context, tasks []model.File, cfg *config.Config,
 			handleFailure(task, err, cfg)
 		} else {
 			successfulCount++
-			handleSuccess(task, cfg)
+			handleSuccess(task, output, cfg)
 		}
 	}
 
@@ -14,11 +14,12 @@ type simpleJob struct {
 }
 
 type processResult struct {
-	task model.File
-	err  error
+	task   model.File
+	output compressionOutput
+	err    error
 }
 
-func runWorkerPool(ctx context.Context, tasks []model.File, cfg *config.Config, storage *adapter.StorageAdapter) {
+func runWorkerPool(ctx context.Context, tasks []model.File, cfg *config.Config, storage adapter.Storage) {
 	numWorkers := cfg.NumWorkers
 	if numWorkers <= 0 {
 		numWorkers = 1
@@ -61,7 +62,7 @@ func runWorkerPool(ctx context.Context, tasks []model.File, cfg *config.Config,
 			handleFailure(result.task, result.err, cfg)
 		} else {
 			successfulCount++
-			handleSuccess(result.task, cfg)
+			handleSuccess(result.task, result.output, cfg)
 		}
 	}
 
@@ -79,7 +80,7 @@ func simpleWorker(
 	wg *sync.WaitGroup,
 	cfg *config.Config,
 	workerID int,
-	storage *adapter.StorageAdapter,
+	storage adapter.Storage,
 ) {
 	defer wg.Done()
 
@@ -97,12 +98,12 @@ func simpleWorker(
 				"file", job.task.Name,
 			)
 
-			err := ExecuteCompressionTask(ctx, cfg, job.task, storage)
+			output, err := ExecuteCompressionTask(ctx, cfg, job.task, storage)
 
 			select {
 			case <-ctx.Done():
 				return
-			case results <- processResult{task: job.task, err: err}:
+			case results <- processResult{task: job.task, output: output, err: err}:
 			}
 		}
 	}
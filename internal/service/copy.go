@@ -0,0 +1,60 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// copyObject menyalin satu objek beserta header dan metadata-nya dari src ke
+// dst, lalu memverifikasi ukuran salinan. Checksum yang dikembalikan adalah
+// SHA-256 dari byte yang dibaca dari src.
+func copyObject(ctx context.Context, src, dst adapter.Storage, path string) (adapter.ObjectInfo, string, error) {
+	info, err := src.Stat(ctx, path)
+	if err != nil {
+		return adapter.ObjectInfo{}, "", err
+	}
+
+	reader, err := src.Open(ctx, path)
+	if err != nil {
+		return adapter.ObjectInfo{}, "", err
+	}
+	hash := sha256.New()
+	err = dst.Put(ctx, path, io.TeeReader(reader, hash), adapter.PutOptions{
+		ContentType:        info.ContentType,
+		CacheControl:       info.CacheControl,
+		ContentDisposition: info.ContentDisposition,
+		Metadata:           info.Metadata,
+	})
+	reader.Close()
+	if err != nil {
+		return adapter.ObjectInfo{}, "", fmt.Errorf("gagal menulis salinan: %w", err)
+	}
+
+	copied, err := dst.Stat(ctx, path)
+	if err != nil {
+		return adapter.ObjectInfo{}, "", fmt.Errorf("gagal memverifikasi salinan: %w", err)
+	}
+	if copied.Size != info.Size {
+		return adapter.ObjectInfo{}, "", fmt.Errorf("ukuran salinan tidak cocok: %d != %d", copied.Size, info.Size)
+	}
+	return info, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashObject(ctx context.Context, storage adapter.Storage, path string) (string, int64, error) {
+	reader, err := storage.Open(ctx, path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
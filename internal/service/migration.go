@@ -0,0 +1,361 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MigrationOptions struct {
+	Name        string
+	BatchSize   int
+	DryRun      bool
+	RetryFailed bool
+}
+
+// MigrationReport menghitung file dan objek secara terpisah. Files, Archived
+// dan Failed dihitung per record file; Objects, Copied, Skipped, Missing dan
+// CopiedBytes per objek (file utama dan variannya).
+type MigrationReport struct {
+	Files       int
+	Archived    int
+	Failed      int
+	Objects     int
+	Copied      int
+	Skipped     int
+	Missing     int
+	CopiedBytes int64
+}
+
+type migrationOutcome int
+
+const (
+	outcomeCopied migrationOutcome = iota
+	outcomeSkipped
+	outcomeMissing
+	outcomeFailed
+)
+
+func (r *MigrationReport) addObject(outcome migrationOutcome, size int64) {
+	r.Objects++
+	switch outcome {
+	case outcomeCopied:
+		r.Copied++
+		r.CopiedBytes += size
+	case outcomeSkipped:
+		r.Skipped++
+	case outcomeMissing:
+		r.Missing++
+	}
+}
+
+func (r *MigrationReport) merge(other MigrationReport) {
+	r.Files += other.Files
+	r.Archived += other.Archived
+	r.Failed += other.Failed
+	r.Objects += other.Objects
+	r.Copied += other.Copied
+	r.Skipped += other.Skipped
+	r.Missing += other.Missing
+	r.CopiedBytes += other.CopiedBytes
+}
+
+// isArchived melaporkan file yang objeknya sudah dipindah ke backend arsip.
+// Backend arsip tidak ikut dimigrasi, jadi file tersebut dilewati dan tetap
+// dibaca dari tier arsip.
+func isArchived(file model.File) bool {
+	return file.StorageTier != nil && *file.StorageTier == archiveTier
+}
+
+// MigrateStorage menyalin setiap objek yang direferensikan tabel file dari src
+// ke dst dengan urutan id. Posisi terakhir disimpan di tabel storage_migration
+// per batch sehingga migrasi dengan Name yang sama bisa dilanjutkan, termasuk
+// untuk file baru setelah migrasi selesai. Pada DryRun tidak ada yang ditulis
+// ke dst maupun database. File dengan storage_tier archive dilewati.
+func MigrateStorage(ctx context.Context, cfg *config.Config, src, dst adapter.Storage, opts MigrationOptions) (MigrationReport, error) {
+	var report MigrationReport
+	var checkpoint model.StorageMigration
+
+	if !opts.DryRun {
+		err := database.DB.WithContext(ctx).
+			Where(model.StorageMigration{Name: opts.Name}).
+			FirstOrCreate(&checkpoint).Error
+		if err != nil {
+			return report, fmt.Errorf("gagal memuat checkpoint migrasi: %w", err)
+		}
+		if checkpoint.LastFileID > 0 {
+			slog.Info("Melanjutkan migrasi dari checkpoint", "name", opts.Name, "last_file_id", checkpoint.LastFileID)
+		}
+	}
+
+	if opts.RetryFailed && !opts.DryRun {
+		if err := retryMigrationFailures(ctx, cfg, src, dst, checkpoint, &report); err != nil {
+			return report, err
+		}
+	}
+
+	lastID := checkpoint.LastFileID
+	for ctx.Err() == nil {
+		var files []model.File
+		err := database.DB.WithContext(ctx).
+			Select("id", "type", "name", "storage_tier").
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(opts.BatchSize).
+			Find(&files).Error
+		if err != nil {
+			return report, fmt.Errorf("gagal mengambil data file: %w", err)
+		}
+		if len(files) == 0 {
+			break
+		}
+
+		variants, err := loadVariantsByFile(ctx, files)
+		if err != nil {
+			return report, fmt.Errorf("gagal mengambil data varian file: %w", err)
+		}
+
+		var batch MigrationReport
+		var failures []model.StorageMigrationFailure
+		processedID := lastID
+		for _, file := range files {
+			if ctx.Err() != nil {
+				break
+			}
+			processedID = file.ID
+			if isArchived(file) {
+				batch.Archived++
+				continue
+			}
+			path := cfg.ResolvePath(file.Type, file.Name)
+			if err := migrateFile(ctx, src, dst, fileObjectPaths(path, variants[file.ID]), opts.DryRun, &batch); err != nil {
+				slog.Error("Migrasi: Gagal menyalin file.", "file_id", file.ID, "path", path, "error", err)
+				failures = append(failures, model.StorageMigrationFailure{
+					MigrationID: checkpoint.ID,
+					FileID:      file.ID,
+					Path:        path,
+					LastError:   truncateError(err.Error()),
+				})
+			}
+		}
+
+		if !opts.DryRun {
+			if err := saveMigrationCheckpoint(ctx, checkpoint.ID, processedID, batch, failures); err != nil {
+				return report, err
+			}
+		}
+		report.merge(batch)
+		lastID = processedID
+
+		slog.Info("Migrasi: Batch selesai.",
+			"last_file_id", lastID,
+			"file", report.Files,
+			"file_gagal", report.Failed,
+			"objek_disalin", report.Copied,
+			"objek_dilewati", report.Skipped,
+			"objek_hilang", report.Missing,
+		)
+	}
+
+	if ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+
+	if !opts.DryRun {
+		now := time.Now().Unix()
+		err := database.DB.WithContext(ctx).Model(&model.StorageMigration{}).
+			Where("id = ?", checkpoint.ID).
+			Update("completed_at", now).Error
+		if err != nil {
+			return report, fmt.Errorf("gagal menandai migrasi selesai: %w", err)
+		}
+	}
+	return report, nil
+}
+
+func saveMigrationCheckpoint(ctx context.Context, migrationID, lastFileID int32, batch MigrationReport, failures []model.StorageMigrationFailure) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(failures) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "migration_id"}, {Name: "file_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"path", "last_error", "updated_at"}),
+			}).Create(&failures).Error
+			if err != nil {
+				return fmt.Errorf("gagal mencatat file yang gagal dimigrasi: %w", err)
+			}
+		}
+
+		return tx.Model(&model.StorageMigration{}).Where("id = ?", migrationID).Updates(map[string]interface{}{
+			"last_file_id": lastFileID,
+			"archived":     gorm.Expr("archived + ?", batch.Archived),
+			"copied":       gorm.Expr("copied + ?", batch.Copied),
+			"skipped":      gorm.Expr("skipped + ?", batch.Skipped),
+			"missing":      gorm.Expr("missing + ?", batch.Missing),
+			"failed":       gorm.Expr("failed + ?", batch.Failed),
+			"copied_bytes": gorm.Expr("copied_bytes + ?", batch.CopiedBytes),
+		}).Error
+	})
+}
+
+// retryMigrationFailures memproses ulang file yang gagal pada run sebelumnya.
+// Entri yang berhasil dihapus dari storage_migration_failure.
+func retryMigrationFailures(ctx context.Context, cfg *config.Config, src, dst adapter.Storage, checkpoint model.StorageMigration, report *MigrationReport) error {
+	var failures []model.StorageMigrationFailure
+	err := database.DB.WithContext(ctx).
+		Where("migration_id = ?", checkpoint.ID).
+		Order("file_id ASC").
+		Find(&failures).Error
+	if err != nil {
+		return fmt.Errorf("gagal mengambil daftar file gagal: %w", err)
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	slog.Info("Migrasi: Mengulang file yang gagal sebelumnya.", "jumlah", len(failures))
+
+	for _, failure := range failures {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var file model.File
+		err := database.DB.WithContext(ctx).Select("id", "type", "name", "storage_tier").First(&file, failure.FileID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err != nil || isArchived(file) {
+			database.DB.Delete(&failure)
+			database.DB.Model(&model.StorageMigration{}).Where("id = ?", checkpoint.ID).
+				Update("failed", gorm.Expr("GREATEST(failed - 1, 0)"))
+			continue
+		}
+
+		variants, err := loadVariantsByFile(ctx, []model.File{file})
+		if err != nil {
+			return err
+		}
+
+		path := cfg.ResolvePath(file.Type, file.Name)
+		if err := migrateFile(ctx, src, dst, fileObjectPaths(path, variants[file.ID]), false, report); err != nil {
+			slog.Error("Migrasi: Masih gagal menyalin file.", "file_id", file.ID, "path", path, "error", err)
+			database.DB.Model(&failure).Update("last_error", truncateError(err.Error()))
+			continue
+		}
+
+		database.DB.Delete(&failure)
+		database.DB.Model(&model.StorageMigration{}).Where("id = ?", checkpoint.ID).
+			Update("failed", gorm.Expr("GREATEST(failed - 1, 0)"))
+	}
+	return nil
+}
+
+func loadVariantsByFile(ctx context.Context, files []model.File) (map[int32][]model.FileVariant, error) {
+	ids := make([]int32, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
+	}
+	var variants []model.FileVariant
+	if err := database.DB.WithContext(ctx).Where("file_id IN ?", ids).Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	byFile := make(map[int32][]model.FileVariant, len(files))
+	for _, variant := range variants {
+		byFile[variant.FileID] = append(byFile[variant.FileID], variant)
+	}
+	return byFile, nil
+}
+
+// fileObjectPaths mengembalikan path objek utama diikuti path variannya,
+// yang disimpan di folder yang sama.
+func fileObjectPaths(mainPath string, variants []model.FileVariant) []string {
+	paths := []string{mainPath}
+	for _, variant := range variants {
+		paths = append(paths, filepath.Join(filepath.Dir(mainPath), variant.Name))
+	}
+	return paths
+}
+
+// migrateFile menyalin objek utama dan semua varian sebuah file. Objek yang
+// gagal tidak menghentikan objek lain; file dihitung satu kali sebagai gagal
+// dan error pertama dikembalikan agar dicatat di storage_migration_failure.
+func migrateFile(ctx context.Context, src, dst adapter.Storage, paths []string, dryRun bool, report *MigrationReport) error {
+	report.Files++
+	var firstErr error
+	for _, path := range paths {
+		outcome, size, err := migrateObject(ctx, src, dst, path, dryRun)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", path, err)
+		}
+		report.addObject(outcome, size)
+	}
+	if firstErr != nil {
+		report.Failed++
+	}
+	return firstErr
+}
+
+// migrateObject menyalin satu objek bila belum ada di dst dengan isi yang
+// sama. Objek yang sudah ada dibandingkan ukuran dan SHA-256-nya; salinan baru
+// dibaca ulang dari dst dan checksum-nya harus sama dengan sumber.
+func migrateObject(ctx context.Context, src, dst adapter.Storage, path string, dryRun bool) (migrationOutcome, int64, error) {
+	srcInfo, err := src.Stat(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Migrasi: Objek sumber tidak ditemukan.", "path", path)
+		return outcomeMissing, 0, nil
+	}
+	if err != nil {
+		return outcomeFailed, 0, err
+	}
+
+	dstInfo, err := dst.Stat(ctx, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return outcomeFailed, 0, err
+	}
+	exists := err == nil
+
+	if dryRun {
+		if exists && dstInfo.Size == srcInfo.Size {
+			return outcomeSkipped, 0, nil
+		}
+		slog.Debug("Migrasi (dry-run): Objek akan disalin.", "path", path, "size", srcInfo.Size)
+		return outcomeCopied, srcInfo.Size, nil
+	}
+
+	if exists && dstInfo.Size == srcInfo.Size {
+		srcSum, _, err := hashObject(ctx, src, path)
+		if err != nil {
+			return outcomeFailed, 0, err
+		}
+		dstSum, _, err := hashObject(ctx, dst, path)
+		if err != nil {
+			return outcomeFailed, 0, err
+		}
+		if srcSum == dstSum {
+			return outcomeSkipped, 0, nil
+		}
+	}
+
+	_, srcSum, err := copyObject(ctx, src, dst, path)
+	if err != nil {
+		return outcomeFailed, 0, err
+	}
+	dstSum, _, err := hashObject(ctx, dst, path)
+	if err != nil {
+		return outcomeFailed, 0, fmt.Errorf("gagal membaca ulang salinan: %w", err)
+	}
+	if dstSum != srcSum {
+		return outcomeFailed, 0, fmt.Errorf("checksum salinan tidak cocok: %s != %s", dstSum, srcSum)
+	}
+	return outcomeCopied, srcInfo.Size, nil
+}
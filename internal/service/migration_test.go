@@ -0,0 +1,60 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type failingPutStorage struct {
+	adapter.Storage
+}
+
+func (s failingPutStorage) Put(ctx context.Context, path string, reader io.Reader, opts adapter.PutOptions) error {
+	return errors.New("tujuan tidak tersedia")
+}
+
+func TestMigrateFileCountsFilesAndObjects(t *testing.T) {
+	ctx := context.Background()
+	src := adapter.NewMemoryStorage(nil)
+	dst := adapter.NewMemoryStorage(nil)
+	for path, data := range map[string]string{"a.webp": "main", "a@640w.webp": "variant"} {
+		src.Put(ctx, path, strings.NewReader(data), adapter.PutOptions{})
+	}
+	dst.Put(ctx, "a.webp", strings.NewReader("main"), adapter.PutOptions{})
+
+	var report MigrationReport
+	paths := []string{"a.webp", "a@640w.webp", "a@1280w.webp"}
+	if err := migrateFile(ctx, src, dst, paths, false, &report); err != nil {
+		t.Fatalf("migrateFile: %v", err)
+	}
+	want := MigrationReport{Files: 1, Objects: 3, Copied: 1, Skipped: 1, Missing: 1, CopiedBytes: 7}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+
+	report = MigrationReport{}
+	if err := migrateFile(ctx, src, failingPutStorage{adapter.NewMemoryStorage(nil)}, paths, false, &report); err == nil {
+		t.Fatalf("migrateFile ke tujuan rusak tidak mengembalikan error")
+	}
+	want = MigrationReport{Files: 1, Failed: 1, Objects: 3, Missing: 1}
+	if report != want {
+		t.Errorf("report gagal = %+v, want %+v", report, want)
+	}
+}
+
+func TestIsArchived(t *testing.T) {
+	archive, class := archiveTier, "GLACIER_IR"
+	for _, tt := range []struct {
+		tier *string
+		want bool
+	}{{nil, false}, {&archive, true}, {&class, false}} {
+		if got := isArchived(model.File{StorageTier: tt.tier}); got != tt.want {
+			t.Errorf("isArchived(%v) = %v, want %v", tt.tier, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errMirrorObjectMissing = errors.New("objek tidak ditemukan di mirror saat rekonsiliasi")
+
+// EnqueueMirrorRepair mencatat objek yang gagal ditulis ke mirror. Path yang
+// sudah ada di antrean tidak digandakan.
+func EnqueueMirrorRepair(path string, mirrorErr error) {
+	errorMessage := truncateError(mirrorErr.Error())
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"failed_attempts": 0, "last_error": &errorMessage}),
+	}).Create(&model.MirrorRepair{Path: path, LastError: &errorMessage}).Error
+	if err != nil {
+		slog.Error("Gagal menambahkan antrean repair mirror", "path", path, "error", err)
+	}
+}
+
+func truncateError(message string) string {
+	if len(message) > 250 {
+		return message[:250] + "..."
+	}
+	return message
+}
+
+func ProcessMirrorRepairQueue(ctx context.Context, batchSize int, maxRetries int, mirrored *adapter.MirrorStorage) {
+	slog.Info("Memulai pemroses antrean repair mirror...", "batch_size", batchSize)
+
+	if mirrored == nil {
+		slog.Error("Antrean Repair: Mirror belum dikonfigurasi.")
+		return
+	}
+
+	var queueItems []model.MirrorRepair
+	err := database.DB.WithContext(ctx).Where("failed_attempts < ?", maxRetries).
+		Order("id ASC").
+		Limit(batchSize).
+		Find(&queueItems).Error
+	if err != nil {
+		slog.Error("Antrean Repair: Gagal mengambil data.", "error", err)
+		return
+	}
+
+	if len(queueItems) == 0 {
+		return
+	}
+
+	var doneIDs []int32
+	var failedCount int
+	for _, item := range queueItems {
+		if ctx.Err() != nil {
+			break
+		}
+
+		err := repairMirrorObject(ctx, mirrored, item.Path)
+		if err == nil {
+			doneIDs = append(doneIDs, item.ID)
+			continue
+		}
+
+		slog.Error("Antrean Repair: Gagal menyalin ulang ke mirror.", "path", item.Path, "error", err)
+		errorMessage := truncateError(err.Error())
+		database.DB.Model(&item).Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("failed_attempts + 1"),
+			"last_error":      &errorMessage,
+		})
+		failedCount++
+	}
+
+	if len(doneIDs) > 0 {
+		if err := database.DB.Where("id IN ?", doneIDs).Delete(&model.MirrorRepair{}).Error; err != nil {
+			slog.Error("Antrean Repair: Gagal menghapus entri antrean.", "error", err)
+		}
+	}
+
+	slog.Info("Pemroses antrean repair mirror selesai.", "berhasil", len(doneIDs), "gagal", failedCount)
+}
+
+// repairMirrorObject menyamakan satu path di mirror dengan primary: objek
+// disalin ulang bila belum ada di mirror, dan dihapus dari mirror bila sudah
+// tidak ada di primary (penghapusan mirror yang sebelumnya gagal).
+func repairMirrorObject(ctx context.Context, mirrored *adapter.MirrorStorage, path string) error {
+	inPrimary, err := mirrored.Primary().Exists(ctx, path)
+	if err != nil {
+		return err
+	}
+	if !inPrimary {
+		slog.Info("Antrean Repair: Objek sudah tidak ada di primary, salinan mirror dihapus.", "path", path)
+		return mirrored.Mirror().Delete(ctx, path)
+	}
+
+	exists, err := mirrored.Mirror().Exists(ctx, path)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, _, err = copyObject(ctx, mirrored.Primary(), mirrored.Mirror(), path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if ok, primaryErr := mirrored.Primary().Exists(ctx, path); primaryErr == nil && !ok {
+			slog.Info("Antrean Repair: Objek sudah tidak ada di primary, entri dilewati.", "path", path)
+			return nil
+		}
+	}
+	return err
+}
+
+var (
+	reconcileMu     sync.Mutex
+	reconcileRoot   int
+	reconcileCursor string
+)
+
+// ReconcileMirror mencari objek di folder DIR_* primary yang belum ada di
+// mirror, misalnya karena ditulis sebelum mirror aktif atau karena antrean
+// repair gagal dicatat. Setiap run memeriksa paling banyak
+// MIRROR_RECONCILE_BATCH_SIZE objek dan melanjutkan dari posisi run
+// sebelumnya; setelah satu putaran penuh pencarian dimulai lagi dari awal.
+// Objek yang hilang dimasukkan ke antrean repair.
+func ReconcileMirror(ctx context.Context, cfg *config.Config, mirrored *adapter.MirrorStorage) {
+	batchSize := cfg.MirrorReconcileBatch
+	if mirrored == nil || batchSize <= 0 {
+		return
+	}
+	roots := []string{cfg.DirAttachment, cfg.DirProfile, cfg.DirThumbnail}
+
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+
+	checked, missing := 0, 0
+	for checked < batchSize && ctx.Err() == nil {
+		if reconcileRoot >= len(roots) {
+			reconcileRoot, reconcileCursor = 0, ""
+			slog.Debug("Rekonsiliasi Mirror: Satu putaran selesai.")
+			break
+		}
+
+		page, err := mirrored.Primary().List(ctx, adapter.ListOptions{
+			Prefix:            roots[reconcileRoot] + "/",
+			ContinuationToken: reconcileCursor,
+			MaxKeys:           batchSize - checked,
+		})
+		if err != nil {
+			slog.Error("Rekonsiliasi Mirror: Gagal membaca daftar objek primary.", "prefix", roots[reconcileRoot], "error", err)
+			return
+		}
+
+		for _, object := range page.Objects {
+			checked++
+			exists, err := mirrored.Mirror().Exists(ctx, object.Path)
+			if err != nil {
+				slog.Warn("Rekonsiliasi Mirror: Gagal memeriksa objek di mirror.", "path", object.Path, "error", err)
+				continue
+			}
+			if !exists {
+				EnqueueMirrorRepair(object.Path, errMirrorObjectMissing)
+				missing++
+			}
+		}
+
+		if page.IsTruncated {
+			reconcileCursor = page.NextContinuationToken
+		} else {
+			reconcileRoot, reconcileCursor = reconcileRoot+1, ""
+		}
+	}
+
+	slog.Info("Rekonsiliasi mirror selesai.", "diperiksa", checked, "hilang", missing)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"fmt"
+	"log/slog"
+)
+
+// BuildStorage menyusun storage aplikasi: backend utama, lalu mirror dan tier
+// arsip bila dikonfigurasi. MirrorStorage dikembalikan terpisah untuk job
+// repair dan bernilai nil bila mirror tidak aktif.
+func BuildStorage(cfg *config.Config) (adapter.Storage, *adapter.MirrorStorage, error) {
+	storage, err := adapter.NewStorage(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mirrored *adapter.MirrorStorage
+	if cfg.MirrorStorageMode != "" {
+		mirror, err := adapter.NewBackend(cfg, adapter.BackendSpec{
+			Mode:     cfg.MirrorStorageMode,
+			S3Bucket: cfg.MirrorS3Bucket,
+			Prefix:   cfg.MirrorPrefix,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("gagal menginisialisasi storage mirror: %w", err)
+		}
+		mirrored = adapter.NewMirrorStorage(storage, mirror, adapter.MirrorOptions{
+			Policy:          cfg.MirrorWritePolicy,
+			OnMirrorFailure: EnqueueMirrorRepair,
+		})
+		storage = mirrored
+		slog.Info("Mirror storage aktif", "mode", cfg.MirrorStorageMode, "policy", cfg.MirrorWritePolicy)
+	}
+
+	if cfg.ArchiveStorageMode != "" {
+		archive, err := adapter.NewBackend(cfg, adapter.BackendSpec{
+			Mode:     cfg.ArchiveStorageMode,
+			S3Bucket: cfg.ArchiveS3Bucket,
+			Prefix:   cfg.ArchivePrefix,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("gagal menginisialisasi tier arsip: %w", err)
+		}
+		storage = adapter.NewTieredStorage(storage, archive)
+		slog.Info("Tier arsip aktif", "mode", cfg.ArchiveStorageMode, "prefix", cfg.ArchivePrefix)
+	}
+
+	return storage, mirrored, nil
+}
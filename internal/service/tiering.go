@@ -0,0 +1,156 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+const archiveTier = "archive"
+
+// RunTiering memindahkan file yang sudah lama dan jarang dibaca ke tier arsip.
+// File dianggap dingin bila created_at lebih tua dari TIERING_MIN_AGE dan
+// last_accessed_at (bila diisi API) lebih tua dari TIERING_IDLE_THRESHOLD.
+func RunTiering(ctx context.Context, cfg *config.Config, storage adapter.Storage) {
+	slog.Info("Memulai tugas tiering storage...", "target", cfg.TieringTarget)
+
+	var move func(ctx context.Context, path string) error
+	var tier string
+
+	switch cfg.TieringTarget {
+	case "backend":
+		tiered, ok := storage.(*adapter.TieredStorage)
+		if !ok {
+			slog.Error("Tier arsip belum dikonfigurasi, tiering dibatalkan")
+			return
+		}
+		move = func(ctx context.Context, path string) error {
+			return moveToArchive(ctx, tiered, path)
+		}
+		tier = archiveTier
+	case "storage-class":
+		setter, ok := storage.(adapter.StorageClassSetter)
+		if !ok {
+			slog.Error("Backend storage tidak mendukung storage class, tiering dibatalkan")
+			return
+		}
+		move = func(ctx context.Context, path string) error {
+			return setter.SetStorageClass(ctx, path, cfg.TieringStorageClass)
+		}
+		tier = cfg.TieringStorageClass
+	default:
+		slog.Info("TIERING_TARGET tidak diisi, tiering dilewati")
+		return
+	}
+
+	now := time.Now()
+	ageThreshold := now.Add(-cfg.TieringMinAge).Unix()
+	idleThreshold := now.Add(-cfg.TieringIdleThreshold).Unix()
+
+	var files []model.File
+	err := database.DB.WithContext(ctx).
+		Where("storage_tier IS NULL AND status IN ? AND created_at < ?", []string{"compressed", "failed"}, ageThreshold).
+		Where("used_by_post_id IS NOT NULL OR used_by_user_id IS NOT NULL").
+		Where("last_accessed_at IS NULL OR last_accessed_at < ?", idleThreshold).
+		Order("created_at ASC").
+		Limit(cfg.TieringBatchSize).
+		Find(&files).Error
+	if err != nil {
+		slog.Error("Gagal mengambil kandidat tiering", "error", err)
+		return
+	}
+
+	if len(files) == 0 {
+		slog.Info("Tidak ada file dingin untuk dipindahkan.")
+		return
+	}
+	slog.Info(fmt.Sprintf("Ditemukan %d file dingin.", len(files)))
+
+	variantPaths, err := loadVariantPaths(ctx, cfg, files)
+	if err != nil {
+		slog.Error("Gagal mengambil varian kandidat tiering", "error", err)
+		return
+	}
+
+	moved := 0
+	for _, file := range files {
+		if ctx.Err() != nil {
+			slog.Warn("Tiering dihentikan karena context selesai", "error", ctx.Err())
+			break
+		}
+
+		path := cfg.ResolvePath(file.Type, file.Name)
+		if err := move(ctx, path); err != nil {
+			if errors.Is(err, errors.ErrUnsupported) {
+				slog.Error("Backend storage tidak mendukung storage class, tiering dibatalkan", "error", err)
+				break
+			}
+			slog.Error("Gagal memindahkan file ke tier arsip", "file_id", file.ID, "path", path, "error", err)
+			continue
+		}
+		if err := moveVariants(ctx, move, variantPaths[file.ID]); err != nil {
+			slog.Error("Gagal memindahkan varian file ke tier arsip", "file_id", file.ID, "error", err)
+			continue
+		}
+
+		err := database.DB.WithContext(ctx).Model(&model.File{}).
+			Where("id = ?", file.ID).
+			Update("storage_tier", tier).Error
+		if err != nil {
+			slog.Error("Gagal mencatat tier file", "file_id", file.ID, "error", err)
+			continue
+		}
+		slog.Debug("File dipindahkan ke tier arsip", "file_id", file.ID, "path", path, "tier", tier)
+		moved++
+	}
+
+	slog.Info("Tiering storage selesai", "dipindahkan", moved, "total", len(files))
+}
+
+// moveVariants memindahkan objek varian bersama file utamanya. Varian yang
+// objeknya sudah tidak ada dilewati; file baru ditandai pindah tier setelah
+// semua variannya ikut pindah.
+func moveVariants(ctx context.Context, move func(ctx context.Context, path string) error, paths []string) error {
+	for _, path := range paths {
+		err := move(ctx, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Objek varian tidak ditemukan saat tiering", "path", path)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// moveToArchive menyalin objek ke archive, memverifikasi ukurannya, lalu
+// menghapusnya dari primary. Objek yang sudah tidak ada di primary tetapi ada
+// di archive (misalnya objek dedup yang dipakai bersama) dianggap sudah pindah.
+func moveToArchive(ctx context.Context, tiered *adapter.TieredStorage, path string) error {
+	primary, archive := tiered.Primary(), tiered.Archive()
+
+	_, _, err := copyObject(ctx, primary, archive, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		archived, archiveErr := archive.Exists(ctx, path)
+		if archiveErr != nil {
+			return archiveErr
+		}
+		if archived {
+			return nil
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return primary.Delete(ctx, path)
+}
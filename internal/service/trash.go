@@ -0,0 +1,356 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	trashStampLayout     = "20060102T150405Z"
+	trashCopyConcurrency = 8
+
+	trashReasonCleanup  = "cleanup"
+	trashReasonDeletion = "deletion-queue"
+)
+
+// removedObject adalah hasil removeObjects. TrashPath kosong berarti objek
+// dihapus permanen (trash nonaktif) atau memang sudah tidak ada.
+type removedObject struct {
+	Path      string
+	TrashPath string
+	Err       error
+}
+
+func trashPath(cfg *config.Config, path string, at time.Time) string {
+	return filepath.Join(cfg.TrashPrefix, at.UTC().Format(trashStampLayout), path)
+}
+
+// removeObjects menghapus objek, atau memindahkannya ke TRASH_PREFIX/<waktu>/
+// bila TRASH_ENABLED aktif sehingga masih bisa dipulihkan sampai dipurge.
+// Pemindahan dilakukan dengan menyalin setiap objek ke trash lalu menghapus
+// semua aslinya dengan satu DeleteMany, sehingga penghapusan tetap di-batch.
+func removeObjects(ctx context.Context, cfg *config.Config, storage adapter.Storage, paths []string) []removedObject {
+	results := make([]removedObject, len(paths))
+
+	if !cfg.TrashEnabled {
+		for i, result := range storage.DeleteMany(ctx, paths) {
+			results[i] = removedObject{Path: result.Path, Err: result.Err}
+		}
+		return results
+	}
+
+	at := time.Now()
+	sem := make(chan struct{}, trashCopyConcurrency)
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			target := trashPath(cfg, path, at)
+			err := adapter.Copy(ctx, storage, path, target)
+			switch {
+			case err == nil:
+				results[i] = removedObject{Path: path, TrashPath: target}
+			case errors.Is(err, fs.ErrNotExist):
+				slog.Debug("Objek tidak ditemukan saat disalin ke trash", "path", path)
+				results[i] = removedObject{Path: path}
+			default:
+				results[i] = removedObject{Path: path, Err: err}
+			}
+		}(i, path)
+	}
+	wg.Wait()
+
+	var originals []string
+	var indexes []int
+	for i, result := range results {
+		if result.Err == nil && result.TrashPath != "" {
+			originals = append(originals, result.Path)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(originals) == 0 {
+		return results
+	}
+
+	// Salinan trash dari objek yang gagal dihapus dibuang lagi agar tidak
+	// tertinggal tanpa entri trash.
+	var orphaned []string
+	for j, deleted := range storage.DeleteMany(ctx, originals) {
+		if deleted.Err == nil {
+			continue
+		}
+		i := indexes[j]
+		orphaned = append(orphaned, results[i].TrashPath)
+		results[i] = removedObject{Path: results[i].Path, Err: deleted.Err}
+	}
+	if len(orphaned) > 0 {
+		for _, deleted := range storage.DeleteMany(ctx, orphaned) {
+			if deleted.Err != nil {
+				slog.Warn("Gagal menghapus salinan trash dari objek yang gagal dihapus", "path", deleted.Path, "error", deleted.Err)
+			}
+		}
+	}
+
+	return results
+}
+
+func newTrashEntry(reason string, fileID *int32, removed removedObject, snapshot *model.File, at time.Time) (model.TrashEntry, error) {
+	entry := model.TrashEntry{
+		FileID:       fileID,
+		Reason:       reason,
+		OriginalPath: removed.Path,
+		DeletedAt:    at.Unix(),
+	}
+	if removed.TrashPath != "" {
+		entry.TrashPath = &removed.TrashPath
+	}
+	if snapshot != nil {
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return entry, err
+		}
+		encoded := string(data)
+		entry.FileSnapshot = &encoded
+	}
+	return entry, nil
+}
+
+// PurgeTrash menghapus permanen objek trash yang lebih tua dari
+// TRASH_RETENTION. Salinan trash dari file yang sudah diarsipkan ditulis ke
+// tier arsip, sedangkan List TieredStorage hanya melihat primary, sehingga
+// tier arsip dipurge tersendiri.
+func PurgeTrash(ctx context.Context, cfg *config.Config, storage adapter.Storage) {
+	slog.Info("Memulai purge trash...", "retention", cfg.TrashRetention.String())
+
+	purged := purgeTrashIn(ctx, cfg, storage)
+	if tiered, ok := storage.(*adapter.TieredStorage); ok {
+		purged += purgeTrashIn(ctx, cfg, tiered.Archive())
+	}
+
+	slog.Info("Purge trash selesai.", "dihapus", purged)
+}
+
+// purgeTrashIn memurge trash di satu backend. Folder trash diberi nama
+// timestamp sehingga List sudah terurut dari yang tertua dan iterasi berhenti
+// di objek pertama yang belum kedaluwarsa.
+func purgeTrashIn(ctx context.Context, cfg *config.Config, storage adapter.Storage) int {
+	prefix := filepath.ToSlash(filepath.Clean(cfg.TrashPrefix)) + "/"
+	cutoff := time.Now().Add(-cfg.TrashRetention)
+
+	purged := 0
+	token := ""
+	for ctx.Err() == nil {
+		page, err := storage.List(ctx, adapter.ListOptions{
+			Prefix:            prefix,
+			ContinuationToken: token,
+			MaxKeys:           cfg.TrashPurgeBatchSize,
+		})
+		if err != nil {
+			slog.Error("Purge Trash: Gagal membaca daftar objek.", "error", err)
+			return purged
+		}
+
+		var expired []string
+		reachedLive := false
+		for _, obj := range page.Objects {
+			stamp, ok := parseTrashStamp(prefix, obj.Path)
+			if !ok {
+				slog.Debug("Purge Trash: Path tidak dikenali, dilewati.", "path", obj.Path)
+				continue
+			}
+			if !stamp.Before(cutoff) {
+				reachedLive = true
+				break
+			}
+			expired = append(expired, obj.Path)
+		}
+
+		purged += purgeTrashObjects(ctx, storage, expired)
+
+		if reachedLive || !page.IsTruncated {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	return purged
+}
+
+func parseTrashStamp(prefix, path string) (time.Time, bool) {
+	rest := strings.TrimPrefix(filepath.ToSlash(path), prefix)
+	stamp, _, found := strings.Cut(rest, "/")
+	if !found {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(trashStampLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+func purgeTrashObjects(ctx context.Context, storage adapter.Storage, paths []string) int {
+	if len(paths) == 0 {
+		return 0
+	}
+
+	var purgedPaths []string
+	for _, result := range storage.DeleteMany(ctx, paths) {
+		if result.Err != nil {
+			slog.Error("Purge Trash: Gagal menghapus objek.", "path", result.Path, "error", result.Err)
+			continue
+		}
+		purgedPaths = append(purgedPaths, result.Path)
+	}
+
+	if len(purgedPaths) > 0 {
+		err := database.DB.Model(&model.TrashEntry{}).
+			Where("trash_path IN ? AND purged_at IS NULL", purgedPaths).
+			Update("purged_at", time.Now().Unix()).Error
+		if err != nil {
+			slog.Error("Purge Trash: Gagal menandai entri trash.", "error", err)
+		}
+	}
+	return len(purgedPaths)
+}
+
+func ListTrashEntries(ctx context.Context, limit int) ([]model.TrashEntry, error) {
+	var entries []model.TrashEntry
+	err := database.DB.WithContext(ctx).
+		Where("restored_at IS NULL AND purged_at IS NULL").
+		Order("id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// RestoreFromTrash mengembalikan objek ke path asalnya dan, untuk entri dari
+// cleanup, membuat ulang record file dengan id yang sama. Objek dipindahkan di
+// dalam transaksi sehingga record tidak dibuat bila pemindahan gagal. Entri
+// file utama ikut memulihkan varian yang dihapus bersamanya, baik objek
+// maupun record file_variant.
+func RestoreFromTrash(ctx context.Context, storage adapter.Storage, entryID int32) error {
+	var entry model.TrashEntry
+	if err := database.DB.WithContext(ctx).First(&entry, entryID).Error; err != nil {
+		return fmt.Errorf("entri trash %d tidak ditemukan: %w", entryID, err)
+	}
+	if entry.RestoredAt != nil {
+		return fmt.Errorf("entri trash %d sudah dipulihkan", entryID)
+	}
+	if entry.PurgedAt != nil {
+		return fmt.Errorf("entri trash %d sudah dihapus permanen", entryID)
+	}
+
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if entry.FileSnapshot != nil {
+			var file model.File
+			if err := json.Unmarshal([]byte(*entry.FileSnapshot), &file); err != nil {
+				return fmt.Errorf("snapshot file rusak: %w", err)
+			}
+			var count int64
+			if err := tx.Model(&model.File{}).Where("id = ?", file.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("record file %d sudah ada", file.ID)
+			}
+			if err := tx.Create(&file).Error; err != nil {
+				return fmt.Errorf("gagal membuat ulang record file: %w", err)
+			}
+		}
+
+		entries := []model.TrashEntry{entry}
+		if entry.VariantSnapshot == nil && entry.FileID != nil {
+			var variants []model.TrashEntry
+			err := tx.Where("file_id = ? AND deleted_at = ? AND variant_snapshot IS NOT NULL AND restored_at IS NULL AND purged_at IS NULL", *entry.FileID, entry.DeletedAt).
+				Order("id ASC").
+				Find(&variants).Error
+			if err != nil {
+				return err
+			}
+			entries = append(entries, variants...)
+		}
+
+		restoredAt := time.Now().Unix()
+		for _, restored := range entries {
+			if err := tx.Model(&restored).Update("restored_at", restoredAt).Error; err != nil {
+				return err
+			}
+			if restored.VariantSnapshot != nil {
+				if err := restoreVariantRecord(tx, restored); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, restored := range entries {
+			if err := restoreTrashObject(ctx, storage, restored); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// restoreVariantRecord membuat ulang record file_variant dari snapshot bila
+// file induknya ada.
+func restoreVariantRecord(tx *gorm.DB, entry model.TrashEntry) error {
+	var variant model.FileVariant
+	if err := json.Unmarshal([]byte(*entry.VariantSnapshot), &variant); err != nil {
+		return fmt.Errorf("snapshot varian rusak: %w", err)
+	}
+	var count int64
+	if err := tx.Model(&model.File{}).Where("id = ?", variant.FileID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		slog.Debug("Record file induk tidak ada, record varian tidak dibuat ulang", "file_id", variant.FileID, "name", variant.Name)
+		return nil
+	}
+	variant.ID = 0
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&variant).Error
+}
+
+func restoreTrashObject(ctx context.Context, storage adapter.Storage, entry model.TrashEntry) error {
+	if entry.TrashPath == nil {
+		return nil
+	}
+	exists, err := storage.Exists(ctx, entry.OriginalPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		// Objek yang sudah kembali pada percobaan sebelumnya (transaksi gagal
+		// setelah sebagian objek dipindahkan) tidak dianggap bentrok.
+		inTrash, err := storage.Exists(ctx, *entry.TrashPath)
+		if err != nil {
+			return err
+		}
+		if !inTrash {
+			return nil
+		}
+		return fmt.Errorf("objek sudah ada di path asal %s", entry.OriginalPath)
+	}
+	if err := adapter.Move(ctx, storage, *entry.TrashPath, entry.OriginalPath); err != nil {
+		return fmt.Errorf("gagal memindahkan objek dari trash: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"chrononews-scheduler/internal/adapter"
+	"chrononews-scheduler/internal/config"
+	"chrononews-scheduler/internal/database"
+	"chrononews-scheduler/internal/model"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"time"
+)
+
+// removeFileVariants menghapus objek varian (format lain dan varian lebar)
+// milik file di dirs sebelum objek utamanya. Varian disimpan di folder yang
+// sama dengan objek utama. File yang variannya gagal dihapus dikembalikan
+// agar record dan objek utamanya tetap ada dan dicoba lagi pada run
+// berikutnya.
+func removeFileVariants(ctx context.Context, cfg *config.Config, storage adapter.Storage, reason string, dirs map[int32]string, at time.Time) ([]model.TrashEntry, map[int32]bool, error) {
+	if len(dirs) == 0 {
+		return nil, nil, nil
+	}
+	ids := make([]int32, 0, len(dirs))
+	for id := range dirs {
+		ids = append(ids, id)
+	}
+
+	var variants []model.FileVariant
+	if err := database.DB.WithContext(ctx).Where("file_id IN ?", ids).Find(&variants).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(variants) == 0 {
+		return nil, nil, nil
+	}
+
+	shared, err := sharedVariantPaths(ctx, cfg, variants, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Varian yang masih dipakai file lain hanya dicatat di trash tanpa
+	// menghapus objeknya.
+	var owned []model.FileVariant
+	var paths []string
+	var entries []model.TrashEntry
+	for _, variant := range variants {
+		path := filepath.Join(dirs[variant.FileID], variant.Name)
+		if !shared[path] {
+			owned = append(owned, variant)
+			paths = append(paths, path)
+			continue
+		}
+		slog.Debug("Objek varian masih dipakai file lain, tidak dihapus", "path", path, "file_id", variant.FileID)
+		entry, err := newVariantTrashEntry(reason, variant, removedObject{Path: path}, at)
+		if err != nil {
+			slog.Warn("Gagal membuat snapshot varian untuk trash", "file_id", variant.FileID, "name", variant.Name, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	failed := map[int32]bool{}
+	for i, result := range removeObjects(ctx, cfg, storage, paths) {
+		fileID := owned[i].FileID
+		if result.Err != nil {
+			slog.Error("Gagal hapus varian file", "path", result.Path, "file_id", fileID, "error", result.Err)
+			failed[fileID] = true
+			continue
+		}
+		entry, err := newVariantTrashEntry(reason, owned[i], result, at)
+		if err != nil {
+			slog.Warn("Gagal membuat snapshot varian untuk trash", "file_id", fileID, "name", owned[i].Name, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, failed, nil
+}
+
+// sharedVariantPaths mengembalikan path varian yang juga tercatat milik file
+// di luar ids, misalnya file yang memakai ulang hasil kompresi source identik.
+func sharedVariantPaths(ctx context.Context, cfg *config.Config, variants []model.FileVariant, ids []int32) (map[string]bool, error) {
+	names := make([]string, len(variants))
+	for i, variant := range variants {
+		names[i] = variant.Name
+	}
+
+	var rows []struct {
+		Name       string
+		Type       string
+		ParentName string
+	}
+	err := database.DB.WithContext(ctx).Table("file_variant").
+		Select("file_variant.name, file.type, file.name AS parent_name").
+		Joins("JOIN file ON file.id = file_variant.file_id").
+		Where("file_variant.name IN ? AND file_variant.file_id NOT IN ?", names, ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		shared[filepath.Join(filepath.Dir(cfg.ResolvePath(row.Type, row.ParentName)), row.Name)] = true
+	}
+	return shared, nil
+}
+
+// newVariantTrashEntry mencatat varian yang dihapus beserta snapshot
+// record-nya. Entri tetap dibuat walaupun objeknya sudah tidak ada agar
+// record varian ikut dipulihkan bersama file induknya.
+func newVariantTrashEntry(reason string, variant model.FileVariant, removed removedObject, at time.Time) (model.TrashEntry, error) {
+	fileID := variant.FileID
+	entry, err := newTrashEntry(reason, &fileID, removed, nil, at)
+	if err != nil {
+		return entry, err
+	}
+	data, err := json.Marshal(variant)
+	if err != nil {
+		return entry, err
+	}
+	encoded := string(data)
+	entry.VariantSnapshot = &encoded
+	return entry, nil
+}
+
+// loadVariantPaths mengembalikan path objek varian per file. Varian disimpan
+// di folder yang sama dengan objek utama.
+func loadVariantPaths(ctx context.Context, cfg *config.Config, files []model.File) (map[int32][]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	dirs := make(map[int32]string, len(files))
+	ids := make([]int32, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
+		dirs[file.ID] = filepath.Dir(cfg.ResolvePath(file.Type, file.Name))
+	}
+
+	var variants []model.FileVariant
+	if err := database.DB.WithContext(ctx).Where("file_id IN ?", ids).Order("id ASC").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	paths := make(map[int32][]string, len(files))
+	for _, variant := range variants {
+		paths[variant.FileID] = append(paths[variant.FileID], filepath.Join(dirs[variant.FileID], variant.Name))
+	}
+	return paths, nil
+}
+
+// variantDirsForQueue memilih entri antrean hapus yang variannya ikut
+// dihapus, yaitu entri tanpa KeepVariants. Varian disimpan di folder yang
+// sama dengan objek utama.
+func variantDirsForQueue(items []model.SourceFileToDelete) map[int32]string {
+	dirs := map[int32]string{}
+	for _, item := range items {
+		if !item.KeepVariants {
+			dirs[item.FileID] = filepath.Dir(item.SourcePath)
+		}
+	}
+	return dirs
+}
@@ -9209,6 +9209,17 @@ func (r *Image) HasField(name string) bool {
     return vipsImageHasField(r.image, name)
 }
 
+// RemoveField vips_image_remove removes a metadata field from a copy of the image
+func (r *Image) RemoveField(name string) error {
+	out, err := vipsgenCopy(r.image)
+	if err != nil {
+		return err
+	}
+	vipsImageRemoveField(out, name)
+	r.setImage(out)
+	return nil
+}
+
 // GetBlob vips_image_get_blob retrieves binary metadata from the image by field name
 func (r *Image) GetBlob(name string) ([]byte, error) {
 	return vipsImageGetBlob(r.image, name)